@@ -7,36 +7,95 @@ analysis or other purposes.
 package article
 
 import (
-	"firefly-assignment/config"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Article holds the words of an extracted article along with its detected language,
+// and its publication time if the page has it in its metadata.
+type Article struct {
+	Language  string
+	Words     []string
+	Published time.Time
+}
+
+// ErrNoContent is returned when a page has no element matching the container selector.
+var ErrNoContent = errors.New("could not find article content")
+
+// DefaultContainerSelector is the CSS selector of the article container on Engadget pages.
+const DefaultContainerSelector = ".caas-body"
+
+// Extractor extracts the content of articles from raw HTML.
+type Extractor struct {
+	containerSelector string
+}
+
+// ExtractorOption configures an Extractor.
+type ExtractorOption func(e *Extractor)
+
+// WithContainerSelector sets the CSS selector used to find the container of the article's body.
+func WithContainerSelector(containerSelector string) ExtractorOption {
+	return func(e *Extractor) { e.containerSelector = containerSelector }
+}
+
+// NewExtractor creates an Extractor with the default settings, overridden by the given options.
+//
+// Parameters:
+//   - opts: The options to apply to the Extractor.
+//
+// Returns:
+//   - *Extractor: The configured Extractor.
+func NewExtractor(opts ...ExtractorOption) *Extractor {
+	e := &Extractor{containerSelector: DefaultContainerSelector}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ContainerSelector returns the CSS selector used to find the container of the article's body.
+func (e *Extractor) ContainerSelector() string {
+	return e.containerSelector
+}
+
 // extractArticleContent extracts and returns the textual content of an article
-// from the provided HTML string. It uses a CSS selector to identify the container
-// of the article's body, which is configurable via the application's configuration.
+// from the provided HTML string. It uses the CSS selector of the Extractor to identify
+// the container of the article's body.
 //
 // Parameters:
 //   - body: A string containing the HTML content from which the article text will be extracted.
 //
 // Returns:
 //   - string: The extracted article text.
-//   - error: An error if the article content cannot be found or the HTML cannot be parsed.
-func extractArticleContent(body string) (string, error) {
-	// Create a goquery document from the HTML string
+//   - error: An error if the HTML cannot be parsed, or ErrNoContent if the article content cannot be found.
+func (e *Extractor) extractArticleContent(body string) (string, error) {
+	doc, err := parseHTML(body)
+	if err != nil {
+		return "", err
+	}
+	return e.extractContent(doc)
+}
+
+// parseHTML creates a goquery document from the HTML string.
+func parseHTML(body string) (*goquery.Document, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
 	if err != nil {
-		return "", fmt.Errorf("[ERROR] - error loading HTML: %v", err)
+		return nil, fmt.Errorf("error loading HTML: %w", err)
 	}
+	return doc, nil
+}
 
+// extractContent returns the textual content of the article container of the document.
+func (e *Extractor) extractContent(doc *goquery.Document) (string, error) {
 	// Find the article content. For Engadget, the article content is inside <div> with class `caas-body`
 	// However, you can configure this selector in the config ('container_selector').
-	containerSelector := config.AppConfig.ContainerSelector
-	articleContent := doc.Find(containerSelector)
+	articleContent := doc.Find(e.containerSelector)
 	if articleContent.Length() == 0 {
-		return "", fmt.Errorf("[ERROR] - could not find article content")
+		return "", fmt.Errorf("%w with selector %q", ErrNoContent, e.containerSelector)
 	}
 
 	// Extract and return the text content
@@ -67,8 +126,8 @@ func getWords(article string) []string {
 // Returns:
 //   - []string: A slice containing individual words from the extracted article content.
 //   - error: An error if the article content cannot be extracted.
-func GetArticleWords(rawBody string) ([]string, error) {
-	article, err := extractArticleContent(rawBody)
+func (e *Extractor) GetArticleWords(rawBody string) ([]string, error) {
+	article, err := e.extractArticleContent(rawBody)
 
 	if err != nil {
 		return nil, err
@@ -76,3 +135,41 @@ func GetArticleWords(rawBody string) ([]string, error) {
 
 	return getWords(article), nil
 }
+
+// GetArticle extracts the text content of an article from the provided raw HTML body, detects
+// its language and splits the article into individual words using the tokenizer rules of
+// that language. The publication time is read from the metadata of the page, if any.
+//
+// Parameters:
+//   - rawBody: A string containing the raw HTML body of the article.
+//
+// Returns:
+//   - Article: The language-tagged words of the extracted article content.
+//   - error: An error if the article content cannot be extracted.
+func (e *Extractor) GetArticle(rawBody string) (Article, error) {
+	doc, err := parseHTML(rawBody)
+	if err != nil {
+		return Article{}, err
+	}
+
+	content, err := e.extractContent(doc)
+	if err != nil {
+		return Article{}, err
+	}
+
+	language := DetectLanguage(content)
+	return Article{Language: language, Words: tokenize(content, language), Published: publishedTime(doc)}, nil
+}
+
+// FromText detects the language of a plain text, such as a document submitted without markup,
+// and splits it into individual words using the tokenizer rules of that language.
+//
+// Parameters:
+//   - text: The plain text of the article.
+//
+// Returns:
+//   - Article: The language-tagged words of the text.
+func FromText(text string) Article {
+	language := DetectLanguage(text)
+	return Article{Language: language, Words: tokenize(text, language)}
+}
@@ -1,18 +1,20 @@
 package article
 
 import (
-	"firefly-assignment/config"
+	"errors"
+	"reflect"
 	"testing"
 )
 
 func TestGetArticleWords(t *testing.T) {
-	config.LoadConfig()
+	extractor := NewExtractor()
 
 	tests := []struct {
-		name          string
-		inputHTML     string
-		expectedWords []string
-		expectedError bool
+		name              string
+		inputHTML         string
+		expectedWords     []string
+		expectedError     bool
+		expectedNoContent bool
 	}{
 		{
 			name:          "Valid article content",
@@ -27,28 +29,35 @@ func TestGetArticleWords(t *testing.T) {
 			expectedError: false,
 		},
 		{
-			name:          "Missing article content",
-			inputHTML:     `<html><body><div class="wrong-class">No article content here.</div></body></html>`,
-			expectedWords: nil,
-			expectedError: true,
+			name:              "Missing article content",
+			inputHTML:         `<html><body><div class="wrong-class">No article content here.</div></body></html>`,
+			expectedWords:     nil,
+			expectedError:     true,
+			expectedNoContent: true,
 		},
 		{
-			name:          "Malformed HTML",
-			inputHTML:     `<html><body><div class="caas-body>This is broken HTML</div></body></html>`,
-			expectedWords: nil,
-			expectedError: true,
+			name:              "Malformed HTML",
+			inputHTML:         `<html><body><div class="caas-body>This is broken HTML</div></body></html>`,
+			expectedWords:     nil,
+			expectedError:     true,
+			expectedNoContent: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			words, err := GetArticleWords(tt.inputHTML)
+			words, err := extractor.GetArticleWords(tt.inputHTML)
 
 			// Check if the error matches the expectation
 			if (err != nil) != tt.expectedError {
 				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
 			}
 
+			// Missing content can be told apart from other errors
+			if errors.Is(err, ErrNoContent) != tt.expectedNoContent {
+				t.Errorf("expected ErrNoContent: %v, got: %v", tt.expectedNoContent, err)
+			}
+
 			// If no error, check if the returned words match the expected result
 			if err == nil && !equal(words, tt.expectedWords) {
 				t.Errorf("expected words: %v, got: %v", tt.expectedWords, words)
@@ -57,6 +66,21 @@ func TestGetArticleWords(t *testing.T) {
 	}
 }
 
+func TestWithContainerSelector(t *testing.T) {
+	extractor := NewExtractor(WithContainerSelector("article .body"))
+	inputHTML := `<html><body><div class="caas-body">Ignored.</div><article><p class="body">Custom container.</p></article></body></html>`
+
+	articleContent, err := extractor.GetArticle(inputHTML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedWords := []string{"Custom", "container."}
+	if !equal(articleContent.Words, expectedWords) {
+		t.Errorf("expected words: %v, got: %v", expectedWords, articleContent.Words)
+	}
+}
+
 // Helper function to compare two slices of strings
 func equal(a, b []string) bool {
 	if len(a) != len(b) {
@@ -69,3 +93,37 @@ func equal(a, b []string) bool {
 	}
 	return true
 }
+
+func TestFromText(t *testing.T) {
+	tests := []struct {
+		name             string
+		text             string
+		expectedLanguage string
+		expectedWords    []string
+	}{
+		{
+			name:             "English text",
+			text:             "The vehicle uses cameras and screens instead of windows, so that passengers can see the world.",
+			expectedLanguage: LanguageEnglish,
+			expectedWords:    []string{"The", "vehicle", "uses", "cameras", "and", "screens", "instead", "of", "windows,", "so", "that", "passengers", "can", "see", "the", "world."},
+		},
+		{
+			name:             "Empty text",
+			text:             "  ",
+			expectedLanguage: DefaultLanguage,
+			expectedWords:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FromText(tt.text)
+			if result.Language != tt.expectedLanguage {
+				t.Errorf("expected language %v, got %v", tt.expectedLanguage, result.Language)
+			}
+			if !reflect.DeepEqual(result.Words, tt.expectedWords) {
+				t.Errorf("expected words %q, got %q", tt.expectedWords, result.Words)
+			}
+		})
+	}
+}
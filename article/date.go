@@ -0,0 +1,90 @@
+package article
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// urlDatePattern matches a date embedded in the path of a URL, such as "/2019/08/25/".
+var urlDatePattern = regexp.MustCompile(`/(\d{4})/(\d{1,2})/(\d{1,2})/`)
+
+// publishedSelectors are the elements that hold the publication time of an article in their metadata,
+// in order of preference, with the attribute that holds the time.
+var publishedSelectors = []struct {
+	selector  string
+	attribute string
+}{
+	{selector: `meta[property="article:published_time"]`, attribute: "content"},
+	{selector: `meta[name="article:published_time"]`, attribute: "content"},
+	{selector: `meta[itemprop="datePublished"]`, attribute: "content"},
+	{selector: `meta[name="date"]`, attribute: "content"},
+	{selector: `time[datetime]`, attribute: "datetime"},
+}
+
+// publishedTimeLayouts are the layouts of the publication times found in metadata.
+var publishedTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02"}
+
+// DateFromURL returns the date embedded in the path of a URL, such as 2019-08-25 for
+// "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/".
+//
+// Parameters:
+//   - url: The URL of the article.
+//
+// Returns:
+//   - time.Time: The date, at midnight UTC.
+//   - bool: True if the URL embeds a valid date.
+func DateFromURL(url string) (time.Time, bool) {
+	match := urlDatePattern.FindStringSubmatch(url)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	year, _ := strconv.Atoi(match[1])
+	month, _ := strconv.Atoi(match[2])
+	day, _ := strconv.Atoi(match[3])
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	// Reject dates that time.Date normalized, such as 2019/13/45.
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// PublishedDate returns the publication date of an article: the time from the metadata of its page,
+// or else the date embedded in its URL.
+//
+// Parameters:
+//   - url: The URL of the article.
+//   - article: The extracted article.
+//
+// Returns:
+//   - time.Time: The publication date.
+//   - bool: True if the publication date is known.
+func PublishedDate(url string, article Article) (time.Time, bool) {
+	if !article.Published.IsZero() {
+		return article.Published, true
+	}
+	return DateFromURL(url)
+}
+
+// publishedTime returns the publication time from the metadata of the page, or the zero time if it has none.
+func publishedTime(doc *goquery.Document) time.Time {
+	for _, published := range publishedSelectors {
+		value, exists := doc.Find(published.selector).First().Attr(published.attribute)
+		if !exists {
+			continue
+		}
+
+		for _, layout := range publishedTimeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
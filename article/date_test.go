@@ -0,0 +1,84 @@
+package article
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateFromURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedDate  time.Time
+		expectedFound bool
+	}{
+		{
+			name:          "Engadget URL",
+			url:           "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/",
+			expectedDate:  time.Date(2019, 8, 25, 0, 0, 0, 0, time.UTC),
+			expectedFound: true,
+		},
+		{
+			name:          "Single-digit month and day",
+			url:           "https://example.com/news/2020/1/5/title",
+			expectedDate:  time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC),
+			expectedFound: true,
+		},
+		{name: "No date", url: "https://example.com/news/title", expectedFound: false},
+		{name: "Invalid date", url: "https://example.com/2019/13/45/title", expectedFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, found := DateFromURL(tt.url)
+			if found != tt.expectedFound || !date.Equal(tt.expectedDate) {
+				t.Errorf("expected %v (%v), got %v (%v)", tt.expectedDate, tt.expectedFound, date, found)
+			}
+		})
+	}
+}
+
+func TestPublishedDate(t *testing.T) {
+	extractor := NewExtractor()
+	url := "https://www.engadget.com/2019/08/25/title/"
+
+	tests := []struct {
+		name         string
+		head         string
+		expectedDate time.Time
+	}{
+		{
+			name:         "Open Graph metadata",
+			head:         `<meta property="article:published_time" content="2019-08-24T22:30:00-04:00">`,
+			expectedDate: time.Date(2019, 8, 25, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name:         "Time element",
+			head:         `<time datetime="2019-08-20">August 20</time>`,
+			expectedDate: time.Date(2019, 8, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "Unparseable metadata falls back to the URL",
+			head:         `<meta property="article:published_time" content="yesterday">`,
+			expectedDate: time.Date(2019, 8, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "No metadata falls back to the URL",
+			expectedDate: time.Date(2019, 8, 25, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articleContent, err := extractor.GetArticle(`<html><head>` + tt.head + `</head><body><div class="caas-body">Words.</div></body></html>`)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			date, found := PublishedDate(url, articleContent)
+			if !found || !date.Equal(tt.expectedDate) {
+				t.Errorf("expected %v, got %v (%v)", tt.expectedDate, date, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package article
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Supported languages, identified by their ISO 639-1 codes.
+const (
+	LanguageEnglish    = "en"
+	LanguageGerman     = "de"
+	LanguageFrench     = "fr"
+	LanguageSpanish    = "es"
+	LanguageItalian    = "it"
+	LanguageDutch      = "nl"
+	LanguagePortuguese = "pt"
+
+	// DefaultLanguage is used when the language of a text cannot be determined.
+	DefaultLanguage = LanguageEnglish
+)
+
+const (
+	// profileSize is the number of top-ranked n-grams kept in each language profile.
+	profileSize = 300
+	// maxDetectionRunes limits how much of an article is used for language detection.
+	maxDetectionRunes = 4096
+	// minDetectionNGrams is the minimum number of n-grams required to attempt a detection.
+	minDetectionNGrams = 20
+)
+
+// languageSamples contains a short reference text for each supported language, used to build
+// the character n-gram profiles at startup.
+var languageSamples = map[string]string{
+	LanguageEnglish: `The company said on Monday that the new phone will be available in stores
+	later this year. It is the first time that they have released a device with such a large
+	screen, and the price is expected to be higher than that of the previous model. According to
+	people familiar with the matter, the launch was delayed because of problems with the supply
+	of parts. The researchers found that the technology could help people who have lost their
+	ability to walk. There are still many questions about how the system works and whether it
+	will be safe enough for everyday use, but the results of the study are very promising.`,
+	LanguageGerman: `Das Unternehmen teilte am Montag mit, dass das neue Telefon noch in diesem
+	Jahr in den Geschäften erhältlich sein wird. Es ist das erste Mal, dass sie ein Gerät mit
+	einem so großen Bildschirm herausbringen, und der Preis wird voraussichtlich höher sein als
+	der des Vorgängermodells. Nach Angaben von Personen, die mit der Angelegenheit vertraut sind,
+	wurde die Markteinführung wegen Problemen bei der Lieferung von Teilen verschoben. Die
+	Forscher fanden heraus, dass die Technologie Menschen helfen könnte, die nicht mehr laufen
+	können. Es gibt noch viele Fragen darüber, wie das System funktioniert und ob es sicher
+	genug für den täglichen Gebrauch ist, aber die Ergebnisse der Studie sind sehr vielversprechend.`,
+	LanguageFrench: `L'entreprise a déclaré lundi que le nouveau téléphone sera disponible dans
+	les magasins plus tard cette année. C'est la première fois qu'elle lance un appareil avec un
+	écran aussi grand, et le prix devrait être plus élevé que celui du modèle précédent. Selon
+	des personnes proches du dossier, le lancement a été retardé en raison de problèmes
+	d'approvisionnement en pièces. Les chercheurs ont découvert que la technologie pourrait aider
+	les personnes qui ont perdu la capacité de marcher. Il reste encore beaucoup de questions sur
+	le fonctionnement du système et sur sa sécurité pour un usage quotidien, mais les résultats
+	de l'étude sont très prometteurs.`,
+	LanguageSpanish: `La empresa dijo el lunes que el nuevo teléfono estará disponible en las
+	tiendas a finales de este año. Es la primera vez que lanzan un dispositivo con una pantalla
+	tan grande, y se espera que el precio sea más alto que el del modelo anterior. Según personas
+	familiarizadas con el asunto, el lanzamiento se retrasó debido a problemas con el suministro
+	de piezas. Los investigadores descubrieron que la tecnología podría ayudar a las personas que
+	han perdido la capacidad de caminar. Todavía hay muchas preguntas sobre cómo funciona el
+	sistema y si será lo suficientemente seguro para el uso diario, pero los resultados del
+	estudio son muy prometedores.`,
+	LanguageItalian: `L'azienda ha dichiarato lunedì che il nuovo telefono sarà disponibile nei
+	negozi entro la fine dell'anno. È la prima volta che lanciano un dispositivo con uno schermo
+	così grande, e il prezzo dovrebbe essere più alto di quello del modello precedente. Secondo
+	persone a conoscenza della questione, il lancio è stato rinviato a causa di problemi nella
+	fornitura dei componenti. I ricercatori hanno scoperto che la tecnologia potrebbe aiutare le
+	persone che hanno perso la capacità di camminare. Ci sono ancora molte domande su come
+	funziona il sistema e se sarà abbastanza sicuro per l'uso quotidiano, ma i risultati dello
+	studio sono molto promettenti.`,
+	LanguageDutch: `Het bedrijf zei maandag dat de nieuwe telefoon later dit jaar in de winkels
+	verkrijgbaar zal zijn. Het is de eerste keer dat ze een apparaat met zo'n groot scherm
+	uitbrengen, en de prijs zal naar verwachting hoger zijn dan die van het vorige model. Volgens
+	mensen die bekend zijn met de zaak werd de lancering uitgesteld vanwege problemen met de
+	levering van onderdelen. De onderzoekers ontdekten dat de technologie mensen zou kunnen
+	helpen die niet meer kunnen lopen. Er zijn nog veel vragen over hoe het systeem werkt en of
+	het veilig genoeg is voor dagelijks gebruik, maar de resultaten van het onderzoek zijn
+	veelbelovend.`,
+	LanguagePortuguese: `A empresa disse na segunda-feira que o novo telefone estará disponível
+	nas lojas ainda este ano. É a primeira vez que eles lançam um aparelho com uma tela tão
+	grande, e o preço deve ser mais alto do que o do modelo anterior. Segundo pessoas
+	familiarizadas com o assunto, o lançamento foi adiado por causa de problemas no fornecimento
+	de peças. Os pesquisadores descobriram que a tecnologia poderia ajudar as pessoas que
+	perderam a capacidade de andar. Ainda há muitas perguntas sobre como o sistema funciona e se
+	ele será seguro o suficiente para o uso diário, mas os resultados do estudo são muito
+	promissores.`,
+}
+
+// languageProfiles maps each supported language to its ranked n-gram profile.
+var languageProfiles = buildLanguageProfiles()
+
+// languageRules describes the tokenizer rules that apply to a language.
+type languageRules struct {
+	// elisions are contracted prefixes (e.g. "l'" in "l'homme") that are removed from words.
+	elisions []string
+	// capitalizedNouns is set for languages where all nouns are capitalized, which means
+	// capitalization cannot be used as a proper-noun signal.
+	capitalizedNouns bool
+}
+
+var rulesByLanguage = map[string]languageRules{
+	LanguageGerman:  {capitalizedNouns: true},
+	LanguageFrench:  {elisions: []string{"l'", "d'", "j'", "m'", "n'", "s'", "t'", "c'", "qu'", "jusqu'", "lorsqu'", "puisqu'"}},
+	LanguageItalian: {elisions: []string{"l'", "d'", "un'", "dell'", "all'", "nell'", "sull'", "dall'", "quest'", "c'"}},
+}
+
+// DetectLanguage identifies the language of the given text by comparing its character n-gram
+// profile against the profiles of the supported languages (Cavnar-Trenkle "out-of-place" distance).
+//
+// Parameters:
+//   - text: The text whose language should be detected.
+//
+// Returns:
+//   - string: The ISO 639-1 code of the closest language, or DefaultLanguage if the text is too short.
+func DetectLanguage(text string) string {
+	runes := []rune(text)
+	if len(runes) > maxDetectionRunes {
+		runes = runes[:maxDetectionRunes]
+	}
+
+	counts := countNGrams(string(runes))
+	if len(counts) < minDetectionNGrams {
+		return DefaultLanguage
+	}
+	profile := rankNGrams(counts)
+
+	bestLanguage := DefaultLanguage
+	bestDistance := -1
+	for language, languageProfile := range languageProfiles {
+		distance := 0
+		for ngram, rank := range profile {
+			if languageRank, exists := languageProfile[ngram]; exists {
+				distance += abs(rank - languageRank)
+			} else {
+				distance += profileSize
+			}
+		}
+		// Break ties deterministically in favor of the alphabetically smaller language code.
+		if bestDistance < 0 || distance < bestDistance || (distance == bestDistance && language < bestLanguage) {
+			bestLanguage = language
+			bestDistance = distance
+		}
+	}
+
+	return bestLanguage
+}
+
+// HasCapitalizedNouns reports whether the language capitalizes all nouns (e.g. German), in which
+// case capitalization should not be used to detect proper nouns.
+//
+// Parameters:
+//   - language: The ISO 639-1 code of the language.
+//
+// Returns:
+//   - bool: True if all nouns are capitalized in the language.
+func HasCapitalizedNouns(language string) bool {
+	return rulesByLanguage[language].capitalizedNouns
+}
+
+// tokenize splits text into words using whitespace as the delimiter, and applies the tokenizer
+// rules of the given language (e.g. removing elided articles in French and Italian).
+//
+// Parameters:
+//   - text: The text to split into words.
+//   - language: The ISO 639-1 code of the language of the text.
+//
+// Returns:
+//   - []string: A slice containing the individual words.
+func tokenize(text string, language string) []string {
+	words := getWords(text)
+	rules := rulesByLanguage[language]
+	if len(rules.elisions) == 0 {
+		return words
+	}
+
+	for i, word := range words {
+		words[i] = removeElision(word, rules.elisions)
+	}
+	return words
+}
+
+// removeElision strips a contracted prefix from the word, handling both straight and typographic
+// apostrophes and preserving the case of the remaining word.
+func removeElision(word string, elisions []string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+	for _, elision := range elisions {
+		if strings.HasPrefix(normalized, elision) && len(normalized) > len(elision) {
+			// The apostrophe may be a multi-byte rune in the original word, so cut after it.
+			if i := strings.IndexAny(word, "'’"); i >= 0 {
+				_, size := utf8.DecodeRuneInString(word[i:])
+				return word[i+size:]
+			}
+		}
+	}
+	return word
+}
+
+// buildLanguageProfiles builds the ranked n-gram profile for every language sample.
+func buildLanguageProfiles() map[string]map[string]int {
+	profiles := make(map[string]map[string]int, len(languageSamples))
+	for language, sample := range languageSamples {
+		profiles[language] = rankNGrams(countNGrams(sample))
+	}
+	return profiles
+}
+
+// countNGrams counts the character uni-, bi- and trigrams of every word in the text. Words are
+// lowercased and padded with spaces so that word boundaries contribute to the profile.
+func countNGrams(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	for _, word := range words {
+		padded := []rune(" " + word + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				ngram := string(padded[i : i+n])
+				if ngram == " " {
+					continue
+				}
+				counts[ngram]++
+			}
+		}
+	}
+	return counts
+}
+
+// rankNGrams orders the n-grams by frequency and returns the rank of the top profileSize n-grams.
+func rankNGrams(counts map[string]int) map[string]int {
+	ngrams := make([]string, 0, len(counts))
+	for ngram := range counts {
+		ngrams = append(ngrams, ngram)
+	}
+	sort.Slice(ngrams, func(i, j int) bool {
+		if counts[ngrams[i]] != counts[ngrams[j]] {
+			return counts[ngrams[i]] > counts[ngrams[j]]
+		}
+		return ngrams[i] < ngrams[j]
+	})
+
+	if len(ngrams) > profileSize {
+		ngrams = ngrams[:profileSize]
+	}
+
+	ranks := make(map[string]int, len(ngrams))
+	for rank, ngram := range ngrams {
+		ranks[ngram] = rank
+	}
+	return ranks
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,99 @@
+package article
+
+import (
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "English text",
+			input:    "Sony and Yamaha have built a self-driving cart that is meant for sightseeing. The vehicle uses cameras and screens instead of windows so that passengers can see the world around them.",
+			expected: LanguageEnglish,
+		},
+		{
+			name:     "German text",
+			input:    "Sony und Yamaha haben einen selbstfahrenden Wagen gebaut, der für Besichtigungen gedacht ist. Das Fahrzeug verwendet Kameras und Bildschirme statt Fenster, damit die Fahrgäste die Welt um sich herum sehen können.",
+			expected: LanguageGerman,
+		},
+		{
+			name:     "French text",
+			input:    "Sony et Yamaha ont construit un chariot autonome destiné aux visites touristiques. Le véhicule utilise des caméras et des écrans au lieu de fenêtres pour que les passagers puissent voir le monde qui les entoure.",
+			expected: LanguageFrench,
+		},
+		{
+			name:     "Spanish text",
+			input:    "Sony y Yamaha han construido un carro autónomo pensado para hacer turismo. El vehículo utiliza cámaras y pantallas en lugar de ventanas para que los pasajeros puedan ver el mundo que los rodea.",
+			expected: LanguageSpanish,
+		},
+		{
+			name:     "Text too short",
+			input:    "Hola",
+			expected: DefaultLanguage,
+		},
+		{
+			name:     "Empty text",
+			input:    "",
+			expected: DefaultLanguage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DetectLanguage(tt.input)
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		language string
+		expected []string
+	}{
+		{
+			name:     "English text is split on whitespace",
+			input:    "It's the company's phone",
+			language: LanguageEnglish,
+			expected: []string{"It's", "the", "company's", "phone"},
+		},
+		{
+			name:     "French elisions are removed",
+			input:    "L'entreprise d’Apple qu'elle aime",
+			language: LanguageFrench,
+			expected: []string{"entreprise", "Apple", "elle", "aime"},
+		},
+		{
+			name:     "Italian elisions are removed",
+			input:    "dell'anno l'azienda",
+			language: LanguageItalian,
+			expected: []string{"anno", "azienda"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tokenize(tt.input, tt.language)
+			if !equal(result, tt.expected) {
+				t.Errorf("expected words: %v, got: %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHasCapitalizedNouns(t *testing.T) {
+	if !HasCapitalizedNouns(LanguageGerman) {
+		t.Errorf("expected German to capitalize nouns")
+	}
+	if HasCapitalizedNouns(LanguageEnglish) {
+		t.Errorf("expected English not to capitalize nouns")
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Package checkpoint records the completed URLs of a job and their per-URL counts in a journal file,
+so that an interrupted job can be resumed without fetching the completed URLs again.
+The journal is newline-delimited JSON with one Entry per completed URL. Entries are buffered
+and flushed to the file periodically, so that a crash loses at most the last interval.
+*/
+package checkpoint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"firefly-assignment/utils"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultFlushInterval is how often the buffered entries of a Journal are flushed to the file by default.
+const DefaultFlushInterval = 5 * time.Second
+
+// Entry is the result of a completed URL. Published is the zero time if the publication date is unknown.
+type Entry struct {
+	URL       string                 `json:"url"`
+	Language  string                 `json:"language"`
+	Words     int                    `json:"words"`
+	Published time.Time              `json:"published"`
+	Counts    utils.WordFrequencyMap `json:"counts"`
+	Entities  utils.WordFrequencyMap `json:"entities,omitempty"`
+}
+
+// Journal appends the entries of completed URLs to a checkpoint file.
+type Journal struct {
+	file          *os.File
+	writer        *bufio.Writer
+	mutex         sync.Mutex
+	flushInterval time.Duration
+	done          chan struct{}
+	stopped       sync.WaitGroup
+}
+
+// Option configures a Journal.
+type Option func(j *Journal)
+
+// WithFlushInterval sets how often the buffered entries are flushed to the file.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(j *Journal) { j.flushInterval = interval }
+}
+
+// Open opens the checkpoint file at the given path for a new job, or to resume a job.
+// When resuming, the entries recorded so far are returned, and new entries are appended after them;
+// a last entry that was only partially written when the job died is discarded. Otherwise, the file is truncated.
+//
+// Parameters:
+//   - path: The path of the checkpoint file.
+//   - resume: Whether to resume the job recorded in the file, if it exists.
+//   - opts: The options to apply to the Journal.
+//
+// Returns:
+//   - *Journal: The opened Journal, which must be closed to flush the last entries.
+//   - []Entry: The entries recorded so far, in the order they were recorded, or nil if not resuming.
+//   - error: An error if the file cannot be read, repaired or opened.
+func Open(path string, resume bool, opts ...Option) (*Journal, []Entry, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	var entries []Entry
+	if resume {
+		var err error
+		entries, err = load(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open checkpoint file: %w", err)
+	}
+
+	j := &Journal{file: file, writer: bufio.NewWriter(file), flushInterval: DefaultFlushInterval, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	j.stopped.Add(1)
+	go j.flushPeriodically()
+	return j, entries, nil
+}
+
+// load reads the entries of the checkpoint file at the given path, and truncates the file after the
+// last complete entry. A missing file has no entries.
+func load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint file: %w", err)
+	}
+
+	var entries []Entry
+	var valid int
+	for valid < len(data) {
+		end := bytes.IndexByte(data[valid:], '\n')
+		if end < 0 {
+			break // The last entry was cut off while it was written
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(data[valid:valid+end], &entry); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint entry at byte %v: %w", valid, err)
+		}
+		entries = append(entries, entry)
+		valid += end + 1
+	}
+
+	if valid < len(data) {
+		if err := os.Truncate(path, int64(valid)); err != nil {
+			return nil, fmt.Errorf("could not repair checkpoint file: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+// Record adds the entry of a completed URL to the journal. It is safe to call Record concurrently.
+//
+// Parameters:
+//   - entry: The entry of the completed URL.
+//
+// Returns:
+//   - error: An error if the entry cannot be encoded or written.
+func (j *Journal) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode checkpoint entry: %w", err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if _, err := j.writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write checkpoint entry: %w", err)
+	}
+	return nil
+}
+
+// Flush writes the buffered entries to the checkpoint file.
+//
+// Returns:
+//   - error: An error if the entries cannot be written.
+func (j *Journal) Flush() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := j.writer.Flush(); err != nil {
+		return fmt.Errorf("could not flush checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// Close stops the periodic flushes, flushes the buffered entries and closes the checkpoint file.
+//
+// Returns:
+//   - error: An error if the entries cannot be written or the file cannot be closed.
+func (j *Journal) Close() error {
+	close(j.done)
+	j.stopped.Wait()
+
+	flushErr := j.Flush()
+	if err := j.file.Close(); err != nil && flushErr == nil {
+		return fmt.Errorf("could not close checkpoint file: %w", err)
+	}
+	return flushErr
+}
+
+// flushPeriodically flushes the buffered entries every flush interval until the journal is closed.
+func (j *Journal) flushPeriodically() {
+	defer j.stopped.Done()
+
+	ticker := time.NewTicker(j.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.Flush() // A failed flush is retried on the next tick, and reported by Close
+		case <-j.done:
+			return
+		}
+	}
+}
+
+// Pending indexes the entries of a resumed job by URL, so that each URL of the job is skipped
+// as many times as it was completed.
+type Pending map[string][]Entry
+
+// NewPending indexes the given entries by URL.
+//
+// Parameters:
+//   - entries: The entries recorded in the checkpoint file.
+//
+// Returns:
+//   - Pending: The entries, indexed by URL.
+func NewPending(entries []Entry) Pending {
+	pending := make(Pending)
+	for _, entry := range entries {
+		pending[entry.URL] = append(pending[entry.URL], entry)
+	}
+	return pending
+}
+
+// Take removes and returns the next entry of the given URL, if it has one left.
+//
+// Parameters:
+//   - url: The URL of the job.
+//
+// Returns:
+//   - Entry: The next entry of the URL.
+//   - bool: True if the URL was completed, and must be skipped.
+func (p Pending) Take(url string) (Entry, bool) {
+	entries := p[url]
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+
+	if len(entries) == 1 {
+		delete(p, url)
+	} else {
+		p[url] = entries[1:]
+	}
+	return entries[0], true
+}
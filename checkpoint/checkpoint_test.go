@@ -0,0 +1,145 @@
+package checkpoint
+
+import (
+	"firefly-assignment/utils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	first := Entry{URL: "https://example.com/a", Language: "en", Words: 3, Counts: utils.WordFrequencyMap{"apple": 2}}
+	second := Entry{URL: "https://example.com/b", Language: "de", Words: 1, Published: time.Date(2019, 8, 25, 0, 0, 0, 0, time.UTC), Counts: utils.WordFrequencyMap{"haus": 1}, Entities: utils.WordFrequencyMap{"Berlin": 1}}
+	firstLine := `{"url":"https://example.com/a","language":"en","words":3,"counts":{"apple":2}}` + "\n"
+
+	tests := []struct {
+		name             string
+		existing         string
+		resume           bool
+		expectedEntries  []Entry
+		expectedContents string
+	}{
+		{
+			name:             "New run truncates the file",
+			existing:         firstLine,
+			resume:           false,
+			expectedEntries:  nil,
+			expectedContents: `{"url":"https://example.com/b","language":"de","words":1,"published":"2019-08-25T00:00:00Z","counts":{"haus":1},"entities":{"Berlin":1}}` + "\n",
+		},
+		{
+			name:             "Resume without a file",
+			resume:           true,
+			expectedEntries:  nil,
+			expectedContents: `{"url":"https://example.com/b","language":"de","words":1,"published":"2019-08-25T00:00:00Z","counts":{"haus":1},"entities":{"Berlin":1}}` + "\n",
+		},
+		{
+			name:             "Resume appends to the recorded entries",
+			existing:         firstLine,
+			resume:           true,
+			expectedEntries:  []Entry{first},
+			expectedContents: firstLine + `{"url":"https://example.com/b","language":"de","words":1,"published":"2019-08-25T00:00:00Z","counts":{"haus":1},"entities":{"Berlin":1}}` + "\n",
+		},
+		{
+			name:             "Resume discards a partially written entry",
+			existing:         firstLine + `{"url":"https://exa`,
+			resume:           true,
+			expectedEntries:  []Entry{first},
+			expectedContents: firstLine + `{"url":"https://example.com/b","language":"de","words":1,"published":"2019-08-25T00:00:00Z","counts":{"haus":1},"entities":{"Berlin":1}}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.ndjson")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			journal, entries, err := Open(path, tt.resume)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.expectedEntries) {
+				t.Errorf("expected entries %+v, got %+v", tt.expectedEntries, entries)
+			}
+
+			if err := journal.Record(second); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := journal.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			contents, _ := os.ReadFile(path)
+			if string(contents) != tt.expectedContents {
+				t.Errorf("expected file:\n%q\ngot:\n%q", tt.expectedContents, contents)
+			}
+		})
+	}
+}
+
+func TestOpenInvalidEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.ndjson")
+	os.WriteFile(path, []byte("not json\n"), 0644)
+
+	if _, _, err := Open(path, true); err == nil {
+		t.Error("expected error for an invalid entry")
+	}
+}
+
+func TestJournalFlushesPeriodically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.ndjson")
+	journal, _, err := Open(path, false, WithFlushInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer journal.Close()
+
+	journal.Record(Entry{URL: "https://example.com/a"})
+
+	// The entry reaches the file without closing the journal, as if the process died afterwards.
+	deadline := time.Now().Add(time.Second)
+	for {
+		contents, _ := os.ReadFile(path)
+		if len(contents) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the entry to be flushed periodically")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPendingTake(t *testing.T) {
+	a1 := Entry{URL: "a", Words: 1}
+	a2 := Entry{URL: "a", Words: 2}
+	b := Entry{URL: "b", Words: 3}
+	pending := NewPending([]Entry{a1, b, a2})
+
+	tests := []struct {
+		url           string
+		expectedEntry Entry
+		expectedDone  bool
+	}{
+		{url: "a", expectedEntry: a1, expectedDone: true},
+		{url: "c", expectedDone: false},
+		{url: "a", expectedEntry: a2, expectedDone: true},
+		{url: "a", expectedDone: false},
+		{url: "b", expectedEntry: b, expectedDone: true},
+	}
+
+	for _, tt := range tests {
+		entry, done := pending.Take(tt.url)
+		if done != tt.expectedDone || !reflect.DeepEqual(entry, tt.expectedEntry) {
+			t.Errorf("Take(%q): expected %+v, %v, got %+v, %v", tt.url, tt.expectedEntry, tt.expectedDone, entry, done)
+		}
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending entries left, got %v", pending)
+	}
+}
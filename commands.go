@@ -0,0 +1,580 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/display"
+	"firefly-assignment/jobManager"
+	"firefly-assignment/pageStore"
+	"firefly-assignment/partialResult"
+	"firefly-assignment/pipeline"
+	"firefly-assignment/resultStore"
+	"firefly-assignment/server"
+	"firefly-assignment/tracing"
+	"firefly-assignment/utils"
+	"firefly-assignment/warc"
+	"firefly-assignment/wordBank"
+	"firefly-assignment/wordDiff"
+	"firefly-assignment/wordOps"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// maxInspectTextLength limits how much of the extracted text the inspect command prints.
+const maxInspectTextLength = 1000
+
+// crawlCommand fetches the configured URLs and stores the pages in the page store, without counting any words.
+func crawlCommand(args []string) error {
+	if _, err := loadConfig(programName()+" crawl", args); err != nil {
+		return err
+	}
+
+	store, err := pageStore.Open(pagesDir)
+	if err != nil {
+		return err
+	}
+
+	urls, err := getURLsFromFile()
+	if err != nil {
+		return fmt.Errorf("no URLs to fetch content from: %w", err)
+	}
+
+	watchConfig()
+	interruptErr := processURLs(urls, func(url string, body string, trace *tracing.Trace) error {
+		defer trace.Since("store", time.Now())
+		return store.Save(url, body)
+	})
+
+	finishSummary(len(urls))
+	printSummary("Stored")
+	fmt.Fprintf(os.Stderr, "Pages directory: %v\n", pagesDir)
+	saveSummary()
+	return interruptErr
+}
+
+// analyzeCommand counts the words of the pages in the page store and prints the results.
+func analyzeCommand(args []string) error {
+	if _, err := loadConfig(programName()+" analyze", args); err != nil {
+		return err
+	}
+
+	initializeWordBanks()
+
+	store, err := pageStore.Open(pagesDir)
+	if err != nil {
+		return err
+	}
+
+	urls := store.URLs()
+	for _, url := range urls {
+		if interruption() != nil {
+			break
+		}
+		body, err := store.Load(url)
+		if err == nil {
+			err = countArticle(url, body, nil)
+		}
+
+		if err != nil {
+			recordError("Failed to analyze URL", url, err)
+			continue
+		}
+		recordProcessed()
+	}
+
+	printResults(len(urls))
+	return interruption()
+}
+
+// replayCommand counts the words of the pages archived in WARC files, without fetching them.
+// Only successful (200) responses are counted, and a URL archived several times is counted once, with its last response.
+func replayCommand(args []string) error {
+	paths, err := loadConfig(programName()+" replay", args)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("usage: %v %v", programName(), commands["replay"].usage)
+	}
+
+	initializeWordBanks()
+
+	var urls []string
+	bodies := make(map[string]string)
+	for _, path := range paths {
+		if err := readArchivedPages(path, func(url string, body string) {
+			if _, exists := bodies[url]; !exists {
+				urls = append(urls, url)
+			}
+			bodies[url] = body
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, url := range urls {
+		if interruption() != nil {
+			break
+		}
+		if err := countArticle(url, bodies[url], nil); err != nil {
+			recordError("Failed to analyze URL", url, err)
+			continue
+		}
+		recordProcessed()
+	}
+
+	printResults(len(urls))
+	return interruption()
+}
+
+// readArchivedPages reads the WARC file at the given path, and hands the URL and body of each
+// successful response over to the given handler.
+func readArchivedPages(path string, handle func(url string, body string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := warc.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read %v: %w", path, err)
+		}
+		if record.Type() != warc.TypeResponse {
+			continue
+		}
+
+		status, body, err := warc.ResponseBody(record)
+		if err != nil {
+			slog.Warn("Skipping archived response", "url", record.TargetURI(), "error", err)
+			continue
+		}
+		if status == http.StatusOK {
+			handle(record.TargetURI(), body)
+		}
+	}
+}
+
+// runsCommand lists the runs stored in the database, or exports the top results of a stored run.
+func runsCommand(args []string) error {
+	positionalArgs, err := loadConfig(programName()+" runs", args)
+	if err != nil {
+		return err
+	}
+
+	if databasePath == "" {
+		return fmt.Errorf("database must be set to list the stored runs")
+	}
+	if len(positionalArgs) == 0 {
+		return fmt.Errorf("missing runs subcommand, usage: %v %v", programName(), commands["runs"].usage)
+	}
+
+	store, err := resultStore.Open(databasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch positionalArgs[0] {
+	case "list":
+		return listRuns(store)
+	case "export":
+		if len(positionalArgs) != 2 {
+			return fmt.Errorf("usage: %v runs export RUN_ID", programName())
+		}
+		id, err := strconv.ParseUint(positionalArgs[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid run ID %q", positionalArgs[1])
+		}
+		return exportRun(store, id)
+	default:
+		return fmt.Errorf("unknown runs subcommand: %v", positionalArgs[0])
+	}
+}
+
+// listRuns prints a table of the stored runs, oldest first.
+func listRuns(store *resultStore.Store) error {
+	runs, err := store.Runs()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tCOMMAND\tSTARTED\tDURATION\tTOTAL\tPROCESSED\tERRORED")
+	for _, run := range runs {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%.1fs\t%d\t%d\t%d\n", run.ID, run.Summary.Command, run.Summary.StartedAt.Format(time.RFC3339),
+			run.Summary.DurationSeconds, run.Summary.Totals.Total, run.Summary.Totals.Processed, run.Summary.Totals.Errored)
+	}
+	return writer.Flush()
+}
+
+// exportRun writes the top N words of a stored run to the output in the configured format, without fetching anything.
+// Report formats render the whole stored summary, with the top N words and entities of the configured `top_results`.
+func exportRun(store *resultStore.Store, id uint64) error {
+	run, err := store.Load(id)
+	if err != nil {
+		return err
+	}
+
+	run.Summary.TopWords = wordOps.GetTopNWords(nResults, run.Words)
+	run.Summary.TopEntities = wordOps.GetTopNEntities(nResults, run.Entities)
+
+	write := func(w io.Writer) error { return formatter.Format(w, run.Summary.TopWords) }
+	if reportFormatter, ok := formatter.(display.ReportFormatter); ok {
+		write = func(w io.Writer) error { return reportFormatter.FormatReport(w, run.Summary) }
+	}
+	return writeOutput(write)
+}
+
+// diffCommand compares the word frequencies of two runs, and writes the top N most significant changes
+// to the output in the configured format.
+func diffCommand(args []string) error {
+	sources, err := loadConfig(programName()+" diff", args)
+	if err != nil {
+		return err
+	}
+	if len(sources) != 2 {
+		return fmt.Errorf("usage: %v %v", programName(), commands["diff"].usage)
+	}
+
+	diffFormatter, ok := formatter.(display.DiffFormatter)
+	if !ok {
+		return fmt.Errorf("output format %q does not support comparisons", outputFormat)
+	}
+
+	var loaded [2]diffSource
+	for i, source := range sources {
+		if loaded[i], err = loadFrequencies(source); err != nil {
+			return err
+		}
+	}
+
+	changes := wordDiff.Compare(loaded[0].words, loaded[1].words)
+	statuses := make(map[string]int)
+	for _, change := range changes {
+		statuses[change.Status]++
+	}
+	fmt.Fprintf(os.Stderr, "Compared %v words: %v new, %v vanished, %v rose, %v fell, %v unchanged\n", len(changes),
+		statuses[wordDiff.StatusNew], statuses[wordDiff.StatusVanished], statuses[wordDiff.StatusRose], statuses[wordDiff.StatusFell], statuses[wordDiff.StatusUnchanged])
+	for i, source := range loaded {
+		if source.failed > 0 {
+			fmt.Fprintf(os.Stderr, "Partial comparison: run %c counts %v of %v URLs, %v failed\n",
+				'A'+i, source.urls-source.failed, source.urls, source.failed)
+		}
+	}
+
+	if len(changes) > nResults {
+		changes = changes[:nResults]
+	}
+	return writeOutput(func(w io.Writer) error { return diffFormatter.FormatDiff(w, changes) })
+}
+
+// diffSource holds the word frequencies of a source of the diff command, with the number of its URLs that
+// could not be counted, so that a partial comparison is reported.
+type diffSource struct {
+	words  utils.WordFrequencyMap
+	urls   int
+	failed int
+}
+
+// loadFrequencies returns the word frequencies of a source of the diff command: the ID of a run stored
+// in `database`, or a source of URLs (see urlSource), which are fetched and counted. The URLs that fail
+// are logged and skipped, and loading fails only when none of them is counted.
+func loadFrequencies(source string) (diffSource, error) {
+	if id, err := strconv.ParseUint(source, 10, 64); err == nil && databasePath != "" {
+		store, err := resultStore.Open(databasePath)
+		if err != nil {
+			return diffSource{}, err
+		}
+		defer store.Close()
+
+		run, err := store.Load(id)
+		if err != nil {
+			return diffSource{}, err
+		}
+		return diffSource{words: run.Words}, nil
+	}
+
+	urls, err := readURLs(source)
+	if err != nil {
+		return diffSource{}, fmt.Errorf("no URLs to fetch content from: %w", err)
+	}
+
+	// Load the word banks only if a URL list is compared.
+	initializeWordBanks()
+	counter := articlePipeline()
+
+	sourceTotals := pipeline.NewTotals()
+	var counted int32
+	err = processURLs(urls, func(url string, body string, trace *tracing.Trace) error {
+		start := time.Now()
+		articleContent, err := counter.Extract(body)
+		trace.Since("parse", start)
+		if err != nil {
+			return err
+		}
+
+		countStart := time.Now()
+		sourceTotals.Add(counter.Count(url, articleContent))
+		trace.Since("count", countStart)
+		atomic.AddInt32(&counted, 1)
+		return nil
+	})
+	if err != nil {
+		return diffSource{}, err
+	}
+
+	// Each failed URL is already logged with its error by processURL.
+	loaded := diffSource{words: sourceTotals.Words, urls: len(urls), failed: len(urls) - int(counted)}
+	if counted == 0 && len(urls) > 0 {
+		return diffSource{}, fmt.Errorf("none of the %v URLs of %v could be counted", len(urls), source)
+	}
+	if loaded.failed > 0 {
+		slog.Warn("Comparing partial counts", "source", source, "urls", loaded.urls, "failed", loaded.failed)
+	}
+	return loaded, nil
+}
+
+// mergeCommand merges the partial results written with `partial_output` into one result, and writes its top N words
+// to the output in the configured format, as if a single run had processed the URLs of all the partial results.
+func mergeCommand(args []string) error {
+	paths, err := loadConfig(programName()+" merge", args)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("usage: %v %v", programName(), commands["merge"].usage)
+	}
+
+	merged := partialResult.New()
+	for _, path := range paths {
+		partial, err := partialResult.Load(path)
+		if err != nil {
+			return err
+		}
+		merged.Merge(partial)
+	}
+	slog.Info("Merged partial results", "files", len(paths), "documents", merged.Documents)
+
+	// Partial results hold no publication dates, so there is nothing to bucket.
+	if trendTracker != nil {
+		slog.Warn("Trends cannot be computed from partial results", "path", trendsOutput)
+		trendTracker = nil
+	}
+
+	wordOps.MergeFrequencies(totals.Words, merged.Counts)
+	wordOps.MergeFrequencies(totals.Entities, merged.Entities)
+	wordOps.MergeFrequencies(totals.DocumentFrequencies, merged.DocumentFrequencies)
+	totals.Languages = merged.Languages
+	summary.Errors = merged.Errors
+	for _, article := range merged.Articles {
+		summary.AddArticle(article)
+	}
+	processedURLs = int32(merged.Totals.Processed)
+	erroredURLs = int32(merged.Totals.Errored)
+
+	printResults(merged.Totals.Total)
+	return nil
+}
+
+// serveCommand serves the HTTP API until the process is interrupted. The jobs submitted to the API share
+// the configured rate limit, and the configured concurrency limit as a budget split fairly between them.
+func serveCommand(args []string) error {
+	if _, err := loadConfig(programName()+" serve", args); err != nil {
+		return err
+	}
+
+	// The word banks are needed by every job, so load them before accepting requests.
+	initializeWordBanks()
+	articlePipeline()
+
+	manager := jobManager.New(fetcher, extractor, validWords,
+		jobManager.WithLimiter(limiter),
+		jobManager.WithMaxConcurrentRequests(maxConcurrentRequests),
+		jobManager.WithMaxRunningJobs(maxRunningJobs),
+		jobManager.WithHistorySize(jobHistory),
+		jobManager.WithTopResults(nResults),
+		jobManager.WithSettings(configLoader.Settings()),
+		jobManager.WithMetrics(jobMetrics),
+	)
+	api := server.New(manager, server.WithOutputFormat(outputFormat))
+	httpServer := &http.Server{Addr: listenAddress, Handler: api.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-jobContext.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Serving the API", "address", listenAddress)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("Stopped serving the API")
+	return nil
+}
+
+// wordBankCommand compiles a word bank from a URL or file, or inspects the configured word banks.
+func wordBankCommand(args []string) error {
+	positionalArgs, err := loadConfig(programName()+" wordbank", args)
+	if err != nil {
+		return err
+	}
+
+	if len(positionalArgs) == 0 {
+		return fmt.Errorf("missing wordbank subcommand, usage: %v %v", programName(), commands["wordbank"].usage)
+	}
+
+	switch positionalArgs[0] {
+	case "compile":
+		if len(positionalArgs) != 2 {
+			return fmt.Errorf("usage: %v wordbank compile SOURCE", programName())
+		}
+		return compileWordBank(positionalArgs[1])
+	case "inspect":
+		return inspectWordBanks(positionalArgs[1:])
+	default:
+		return fmt.Errorf("unknown wordbank subcommand: %v", positionalArgs[0])
+	}
+}
+
+// compileWordBank loads a word list from a URL or file and writes the valid words, sorted and one per line,
+// to the configured output. The result can be used as `word_bank_url` to run without downloading the word bank.
+func compileWordBank(source string) error {
+	compiled, err := bank.Load(source)
+	if err != nil {
+		return err
+	}
+
+	output := os.Stdout
+	if outputPath != "" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if err := wordBank.Write(output, compiled); err != nil {
+		return err
+	}
+
+	slog.Info("Compiled word bank", "words", len(compiled), "source", source)
+	return nil
+}
+
+// inspectWordBanks prints the size of each configured word bank, and for each given word,
+// the word banks that contain it.
+func inspectWordBanks(words []string) error {
+	if err := bank.InitializeLanguages(wordBanksChannel); err != nil {
+		return err
+	}
+	wordBanks := <-wordBanksChannel
+
+	languages := make([]string, 0, len(wordBanks))
+	for language := range wordBanks {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	for _, language := range languages {
+		fmt.Printf("%v: %v words\n", language, len(wordBanks[language]))
+	}
+
+	for _, word := range words {
+		normalizedWord := strings.ToLower(word)
+		var found []string
+		for _, language := range languages {
+			if _, exists := wordBanks[language][normalizedWord]; exists {
+				found = append(found, language)
+			}
+		}
+
+		if len(found) == 0 {
+			fmt.Printf("%q: not in any word bank\n", word)
+		} else {
+			fmt.Printf("%q: in %v\n", word, strings.Join(found, ", "))
+		}
+	}
+
+	return nil
+}
+
+// inspectCommand fetches a single page and shows what the extractor pulls from it and which words count,
+// to help debug the container selector without running a full job.
+func inspectCommand(args []string) error {
+	positionalArgs, err := loadConfig(programName()+" inspect", args)
+	if err != nil {
+		return err
+	}
+
+	if len(positionalArgs) != 1 {
+		return fmt.Errorf("usage: %v %v", programName(), commands["inspect"].usage)
+	}
+	url := positionalArgs[0]
+
+	initializeWordBanks()
+
+	body, err := fetcher.FetchContent(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch URL: %w", err)
+	}
+
+	fmt.Printf("URL: %v\n", url)
+	fmt.Printf("Page size: %v bytes\n", len(body))
+	fmt.Printf("Container selector: %v\n", extractor.ContainerSelector())
+
+	counter := articlePipeline()
+	articleContent, err := counter.Extract(body)
+	if err != nil {
+		return err
+	}
+
+	text := utils.Truncate(strings.Join(articleContent.Words, " "), maxInspectTextLength)
+	fmt.Printf("Language: %v\n", articleContent.Language)
+	fmt.Printf("Extracted words: %v\n", len(articleContent.Words))
+	fmt.Printf("Extracted text:\n%v\n", text)
+
+	result := counter.Count(url, articleContent)
+
+	// Words that are neither counted nor part of an entity are not in the word bank.
+	ignoredWords := wordOps.IgnoredWords(articleContent.Words, counter.WordBank(articleContent.Language),
+		!article.HasCapitalizedNouns(articleContent.Language))
+
+	for _, section := range []struct {
+		title       string
+		frequencies utils.WordFrequencyMap
+	}{
+		{title: "Counted words", frequencies: result.Counts},
+		{title: "Entities", frequencies: result.Entities},
+		{title: "Ignored words", frequencies: ignoredWords},
+	} {
+		fmt.Printf("%v (%v distinct, top %v):\n", section.title, len(section.frequencies), nResults)
+		if err := formatter.Format(os.Stdout, wordOps.GetTopNWords(nResults, section.frequencies)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,61 +1,473 @@
 /*
 Package config provides a centralized way to manage application configuration settings.
-It uses Viper to load configuration values from both default settings and a "config.yaml"
-file.
+It uses Viper to load configuration values from command-line flags, environment variables,
+a "config.yaml" file and default settings, in that order of precedence, and validates the result.
+The loaded configuration builds the components of a job (Fetcher, Extractor and Bank), so that
+the other packages do not depend on the configuration. The defaults of the schema are literals, rather than
+the defaults of the feature packages, so that the configuration only depends on the components it builds.
 */
 package config
 
 import (
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/network"
+	"firefly-assignment/wordBank"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"golang.org/x/time/rate"
 )
 
+// EnvPrefix is the prefix of the environment variables that override configuration values
+// (e.g. FIREFLY_TOP_RESULTS overrides `top_results`).
+const EnvPrefix = "FIREFLY"
+
 // Config structure to hold the configuration
 type Config struct {
-	TopResults            int        `mapstructure:"top_results"`
-	SourceURLFileName     string     `mapstructure:"source_url_filename"`
-	WordBankURL           string     `mapstructure:"word_bank_url"`
-	ContainerSelector     string     `mapstructure:"container_selector"`
-	RequestsPerSecond     rate.Limit `mapstructure:"requests_per_second"`
-	BurstSize             int        `mapstructure:"burst_size"`
-	MaxConcurrentRequests int        `mapstructure:"max_concurrent_requests"`
-	MaxRetries            int        `mapstructure:"max_retries"`
-	MaxRedirects          int        `mapstructure:"max_redirects"`
+	TopResults            int               `mapstructure:"top_results"`
+	SourceURLFileName     string            `mapstructure:"source_url_filename"`
+	Input                 []string          `mapstructure:"input"`
+	PagesDir              string            `mapstructure:"pages_dir"`
+	Output                string            `mapstructure:"output"`
+	OutputFormat          string            `mapstructure:"output_format"`
+	SummaryOutput         string            `mapstructure:"summary_output"`
+	PartialOutput         string            `mapstructure:"partial_output"`
+	Database              string            `mapstructure:"database"`
+	TrendsOutput          string            `mapstructure:"trends_output"`
+	TrendsGranularity     string            `mapstructure:"trends_granularity"`
+	WordBankURL           string            `mapstructure:"word_bank_url"`
+	WordBankURLs          map[string]string `mapstructure:"word_bank_urls"`
+	RemoveStopwords       bool              `mapstructure:"remove_stopwords"`
+	ContainerSelector     string            `mapstructure:"container_selector"`
+	RequestsPerSecond     rate.Limit        `mapstructure:"requests_per_second"`
+	BurstSize             int               `mapstructure:"burst_size"`
+	MaxConcurrentRequests int               `mapstructure:"max_concurrent_requests"`
+	MaxRetries            int               `mapstructure:"max_retries"`
+	MaxRedirects          int               `mapstructure:"max_redirects"`
+	CacheDir              string            `mapstructure:"cache_dir"`
+	CacheTTL              time.Duration     `mapstructure:"cache_ttl"`
+	CacheMaxSize          int               `mapstructure:"cache_max_size"`
+	NoCache               bool              `mapstructure:"no_cache"`
+	Refresh               bool              `mapstructure:"refresh"`
+	WARCOutput            string            `mapstructure:"warc_output"`
+	Checkpoint            string            `mapstructure:"checkpoint"`
+	CheckpointInterval    time.Duration     `mapstructure:"checkpoint_interval"`
+	Resume                bool              `mapstructure:"resume"`
+	ListenAddress         string            `mapstructure:"listen_address"`
+	MaxRunningJobs        int               `mapstructure:"max_running_jobs"`
+	JobHistory            int               `mapstructure:"job_history"`
+	MetricsAddress        string            `mapstructure:"metrics_address"`
+	TraceOutput           string            `mapstructure:"trace_output"`
+	Progress              bool              `mapstructure:"progress"`
+	LogFormat             string            `mapstructure:"log_format"`
+	LogLevel              string            `mapstructure:"log_level"`
+	Quiet                 bool              `mapstructure:"quiet"`
+	Verbose               bool              `mapstructure:"verbose"`
 }
 
-var AppConfig Config
+// LogFormats lists the supported values of `log_format`.
+var LogFormats = []string{"text", "json"}
+
+// OutputFormats lists the supported values of `output_format`, the formats of display.Formats.
+var OutputFormats = []string{"json", "ndjson", "csv", "tsv", "markdown", "table", "html"}
+
+// TrendGranularities lists the supported values of `trends_granularity`, the granularities of trends.Granularities.
+var TrendGranularities = []string{"day", "week", "month"}
+
+// option describes a single configuration setting. The list of options is the schema from which
+// both the default values and the command-line flags are generated.
+type option struct {
+	key          string
+	shorthand    string
+	defaultValue interface{}
+	usage        string
+}
+
+var options = []option{
+	{key: "top_results", shorthand: "n", defaultValue: 10, usage: "Number of top results to display"},
+	{key: "source_url_filename", defaultValue: "endg-urls", usage: "Filename in the 'static' folder that contains the list of URLs"},
+	{key: "input", shorthand: "i", defaultValue: []string{}, usage: "Sources of the URLs: files, glob patterns, URLs of sitemaps or feeds, or '-' for stdin (repeatable, overrides source_url_filename)"},
+	{key: "pages_dir", defaultValue: "pages", usage: "Directory where the crawl command stores pages and the analyze command reads them from"},
+	{key: "output", shorthand: "o", defaultValue: "", usage: "Path of the file to write the results to (default stdout)"},
+	{key: "output_format", shorthand: "f", defaultValue: "json", usage: "Format of the results (" + strings.Join(OutputFormats, ", ") + ")"},
+	{key: "summary_output", defaultValue: "", usage: "Path of the file to write the machine-readable run summary (JSON) to"},
+	{key: "partial_output", defaultValue: "", usage: "Path of the file to write the mergeable partial result (JSON) to, for the merge command"},
+	{key: "database", defaultValue: "", usage: "Path of the database file to store the results of each run in, for the runs command"},
+	{key: "trends_output", defaultValue: "", usage: "Path of the file to write the frequency series of the top words by publication date to (JSON if it ends with .json, CSV otherwise)"},
+	{key: "trends_granularity", defaultValue: "day", usage: "Period of the buckets of the trends (" + strings.Join(TrendGranularities, ", ") + ")"},
+	{key: "word_bank_url", defaultValue: "https://raw.githubusercontent.com/dwyl/english-words/master/words.txt", usage: "URL or file path to fetch a word bank"},
+	{key: "word_bank_urls", defaultValue: map[string]string{}, usage: "Word bank URLs for other languages, keyed by ISO 639-1 code (e.g. de=https://...)"},
+	{key: "remove_stopwords", defaultValue: false, usage: "Exclude common function words (stopwords) from the results"},
+	{key: "container_selector", defaultValue: ".caas-body", usage: "CSS selector used to scrape content"},
+	{key: "requests_per_second", defaultValue: 20.0, usage: "Maximum number of requests per second"},
+	{key: "burst_size", defaultValue: 20, usage: "Maximum burst size for rate limiting"},
+	{key: "max_concurrent_requests", defaultValue: 20, usage: "Maximum number of concurrent requests"},
+	{key: "max_retries", defaultValue: 3, usage: "Maximum number of retries for failed requests"},
+	{key: "max_redirects", defaultValue: 5, usage: "Maximum number of redirects to follow"},
+	{key: "cache_dir", defaultValue: "cache", usage: "Directory of the cache of fetched pages"},
+	{key: "cache_ttl", defaultValue: 24 * time.Hour, usage: "How long fetched pages are kept in the cache"},
+	{key: "cache_max_size", defaultValue: 500, usage: "Maximum size of the cache of fetched pages, in MiB"},
+	{key: "no_cache", defaultValue: false, usage: "Fetch all pages without using the cache"},
+	{key: "refresh", defaultValue: false, usage: "Fetch all pages again, ignoring and refreshing the cache"},
+	{key: "warc_output", defaultValue: "", usage: "Path of the WARC file to archive the HTTP requests and responses to (compressed if it ends with .gz)"},
+	{key: "checkpoint", defaultValue: "", usage: "Path of the file recording the completed URLs and their counts, to resume the run command"},
+	{key: "checkpoint_interval", defaultValue: 5 * time.Second, usage: "How often the completed URLs are flushed to the checkpoint file"},
+	{key: "resume", defaultValue: false, usage: "Skip the URLs completed in the checkpoint file and merge their counts, instead of starting over"},
+	{key: "listen_address", defaultValue: ":8080", usage: "Address that the serve command listens on for API requests"},
+	{key: "max_running_jobs", defaultValue: 2, usage: "Maximum number of jobs that the serve command runs at once, the others waiting in the queue"},
+	{key: "job_history", defaultValue: 100, usage: "Number of finished jobs that the serve command keeps, with their results"},
+	{key: "trace_output", defaultValue: "", usage: "Path of the file to write the tracing spans of the stages of each URL to, as JSON lines"},
+	{key: "metrics_address", defaultValue: "", usage: "Address to serve the Prometheus metrics on at /metrics while a command runs, e.g. :9090 (disabled if empty)"},
+	{key: "progress", defaultValue: true, usage: "Show the progress of the job, live on terminals and as periodic log lines otherwise"},
+	{key: "log_format", defaultValue: "text", usage: "Format of the log messages (" + strings.Join(LogFormats, ", ") + ")"},
+	{key: "log_level", defaultValue: "info", usage: "Minimum level of the log messages (debug, info, warn, error)"},
+	{key: "quiet", shorthand: "q", defaultValue: false, usage: "Only log errors, and hide the progress (overrides log_level)"},
+	{key: "verbose", shorthand: "v", defaultValue: false, usage: "Log debug messages, such as each processed URL (overrides log_level)"},
+}
+
+// Loader loads the configuration of a job from command-line flags, environment variables,
+// a configuration file and default settings. Each Loader has its own state, so that
+// differently-configured jobs can be loaded in the same process.
+type Loader struct {
+	viper      *viper.Viper
+	flags      *pflag.FlagSet
+	configFile string
+}
 
-// LoadConfig loads configuration settings for the application.
+// NewLoader creates a Loader with a flag for every configuration setting, plus a `--config`
+// flag to set the path of the configuration file. Each flag is named after its configuration
+// key, with underscores replaced by dashes (e.g. `top_results` becomes `--top-results`).
+//
+// Parameters:
+//   - name: The name of the command, used in the usage message.
+//
+// Returns:
+//   - *Loader: The Loader, with its flags bound to the configuration keys.
+func NewLoader(name string) *Loader {
+	l := &Loader{viper: viper.New(), flags: pflag.NewFlagSet(name, pflag.ContinueOnError)}
+	l.flags.SortFlags = false
+
+	l.flags.StringVarP(&l.configFile, "config", "c", "", fmt.Sprintf("Path of the configuration file (env: %s, default: config.yaml in %s)", envName("config"), strings.Join(SearchPaths(), ", ")))
+	for _, opt := range options {
+		flagName := strings.ReplaceAll(opt.key, "_", "-")
+		usage := fmt.Sprintf("%s (config: %s, env: %s)", opt.usage, opt.key, envName(opt.key))
+
+		switch value := opt.defaultValue.(type) {
+		case int:
+			l.flags.IntP(flagName, opt.shorthand, value, usage)
+		case float64:
+			l.flags.Float64P(flagName, opt.shorthand, value, usage)
+		case time.Duration:
+			l.flags.DurationP(flagName, opt.shorthand, value, usage)
+		case bool:
+			l.flags.BoolP(flagName, opt.shorthand, value, usage)
+		case string:
+			l.flags.StringP(flagName, opt.shorthand, value, usage)
+		case []string:
+			l.flags.StringSliceP(flagName, opt.shorthand, value, usage)
+		case map[string]string:
+			l.flags.StringToStringP(flagName, opt.shorthand, value, usage)
+		}
+
+		// Only flags that are explicitly set override the other configuration sources.
+		l.viper.BindPFlag(opt.key, l.flags.Lookup(flagName))
+	}
+
+	l.flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n\nFlags:\n%s", name, l.flags.FlagUsages())
+	}
+
+	return l
+}
+
+// ParseFlags parses the command-line arguments into the configuration flags.
+// It must be called before Load for the flags to take effect.
+//
+// Parameters:
+//   - args: The command-line arguments, without the program and command names.
+//
+// Returns:
+//   - []string: The remaining positional (non-flag) arguments.
+//   - error: An error if the arguments are invalid, or pflag.ErrHelp if help was requested.
+func (l *Loader) ParseFlags(args []string) ([]string, error) {
+	if err := l.flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return l.flags.Args(), nil
+}
+
+// Load loads configuration settings for the application.
 // It sets default values for various parameters using Viper and attempts
-// to read from a configuration file named "config.yaml" located in the current directory.
-// If no config file is found, the function proceeds with the default values.
-func LoadConfig() {
+// to read from a configuration file named "config.yaml" located in one of the SearchPaths,
+// or from the file set with the `--config` flag or the FIREFLY_CONFIG environment variable.
+// Environment variables prefixed with EnvPrefix and command-line flags override the file.
+// If no config file is found, the function proceeds with the default values, and ConfigFileUsed returns "".
+//
+// Returns:
+//   - Config: The loaded configuration.
+//   - error: An error if the config file cannot be read or parsed, or if the configuration is invalid.
+func (l *Loader) Load() (Config, error) {
 	// Set default values
-	viper.SetDefault("top_results", 10)
-	viper.SetDefault("source_url_filename", "endg-urls")
-	viper.SetDefault("word_bank_url", "https://raw.githubusercontent.com/dwyl/english-words/master/words.txt")
-	viper.SetDefault("container_selector", ".caas-body")
-	viper.SetDefault("requests_per_second", 20)
-	viper.SetDefault("burst_size", 20)
-	viper.SetDefault("max_concurrent_requests", 20)
-	viper.SetDefault("max_retries", 3)
-	viper.SetDefault("max_redirects", 5)
+	for _, opt := range options {
+		l.viper.SetDefault(opt.key, opt.defaultValue)
+	}
+
+	// Environment variable settings
+	l.viper.SetEnvPrefix(EnvPrefix)
+	l.viper.AutomaticEnv()
 
 	// Configuration file settings
-	viper.SetConfigName("config") // Config file name (without extension)
-	viper.SetConfigType("yaml")   // Config file type
-	viper.AddConfigPath(".")      // Look for config in the current directory
+	path := l.configFile
+	if path == "" {
+		path = os.Getenv(envName("config"))
+	}
+	if path != "" {
+		l.viper.SetConfigFile(path) // Explicit config file path
+	} else {
+		l.viper.SetConfigName("config") // Config file name (without extension)
+		l.viper.SetConfigType("yaml")   // Config file type
+		for _, searchPath := range SearchPaths() {
+			l.viper.AddConfigPath(searchPath)
+		}
+	}
 
 	// Read the config file if available; otherwise, continue with defaults
-	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("[INFO] - no configuration file found, using default values)")
+	if err := l.viper.ReadInConfig(); err != nil {
+		var notFoundErr viper.ConfigFileNotFoundError
+		if path != "" || !errors.As(err, &notFoundErr) {
+			return Config{}, fmt.Errorf("unable to read config file: %w", err)
+		}
+	}
+
+	// Unmarshal the config into a Config struct
+	var c Config
+	if err := l.viper.Unmarshal(&c); err != nil {
+		return Config{}, fmt.Errorf("unable to decode config: %w", err)
+	}
+
+	return c, c.Validate()
+}
+
+// Watch watches the configuration file that was read by Load, and reloads the configuration whenever
+// the file changes. Valid configurations are passed to onChange, and invalid ones to onInvalid, so that
+// a broken edit never reaches a running job. Flags and environment variables keep their precedence
+// over the file. Watch does nothing if no configuration file was read.
+//
+// Parameters:
+//   - onChange: Called with the reloaded configuration when it is valid.
+//   - onInvalid: Called with the validation or decoding error when the reloaded configuration is invalid.
+//
+// Returns:
+//   - bool: True if a configuration file is being watched.
+func (l *Loader) Watch(onChange func(Config), onInvalid func(error)) bool {
+	if l.viper.ConfigFileUsed() == "" {
+		return false
+	}
+
+	l.viper.OnConfigChange(func(_ fsnotify.Event) {
+		var c Config
+		if err := l.viper.Unmarshal(&c); err != nil {
+			onInvalid(fmt.Errorf("unable to decode config: %w", err))
+			return
+		}
+		if err := c.Validate(); err != nil {
+			onInvalid(err)
+			return
+		}
+		onChange(c)
+	})
+	l.viper.WatchConfig()
+	return true
+}
+
+// ConfigFileUsed returns the path of the configuration file read by Load, or "" if no file was found.
+func (l *Loader) ConfigFileUsed() string {
+	return l.viper.ConfigFileUsed()
+}
+
+// Settings returns a snapshot of the configuration settings loaded by Load, keyed by configuration key.
+// Each value has the type of the setting, even when it was set from an environment variable.
+//
+// Returns:
+//   - map[string]interface{}: The configuration settings.
+func (l *Loader) Settings() map[string]interface{} {
+	settings := make(map[string]interface{}, len(options))
+	for _, opt := range options {
+		switch opt.defaultValue.(type) {
+		case int:
+			settings[opt.key] = l.viper.GetInt(opt.key)
+		case float64:
+			settings[opt.key] = l.viper.GetFloat64(opt.key)
+		case time.Duration:
+			settings[opt.key] = l.viper.GetDuration(opt.key).String()
+		case bool:
+			settings[opt.key] = l.viper.GetBool(opt.key)
+		case []string:
+			settings[opt.key] = l.viper.GetStringSlice(opt.key)
+		case map[string]string:
+			settings[opt.key] = l.viper.GetStringMapString(opt.key)
+		default:
+			settings[opt.key] = l.viper.GetString(opt.key)
+		}
+	}
+	return settings
+}
+
+// LoadConfig loads the configuration from environment variables, a configuration file and
+// default settings, without any command-line flags.
+//
+// Returns:
+//   - Config: The loaded configuration.
+//   - error: An error if the config file cannot be read or parsed, or if the configuration is invalid.
+func LoadConfig() (Config, error) {
+	return NewLoader("").Load()
+}
+
+// NewFetcher creates a network.Fetcher with the network and cache settings of the configuration.
+//
+// Parameters:
+//   - extraOpts: Options that depend on the job rather than the configuration, such as an archiver.
+//
+// Returns:
+//   - *network.Fetcher: The configured Fetcher.
+//   - error: An error if the cache cannot be opened.
+func (c Config) NewFetcher(extraOpts ...network.FetcherOption) (*network.Fetcher, error) {
+	opts := []network.FetcherOption{
+		network.WithMaxRetries(c.MaxRetries),
+		network.WithMaxRedirects(c.MaxRedirects),
 	}
 
-	// Unmarshal the config into AppConfig struct
-	if err := viper.Unmarshal(&AppConfig); err != nil {
-		log.Fatalf("Unable to decode into struct: %v", err)
+	if !c.NoCache {
+		cache, err := network.OpenCache(c.CacheDir,
+			network.WithTTL(c.CacheTTL),
+			network.WithMaxSize(int64(c.CacheMaxSize)<<20),
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, network.WithCache(cache), network.WithRefresh(c.Refresh))
+	}
+
+	return network.NewFetcher(append(opts, extraOpts...)...), nil
+}
+
+// NewExtractor creates an article.Extractor with the container selector of the configuration.
+//
+// Returns:
+//   - *article.Extractor: The configured Extractor.
+func (c Config) NewExtractor() *article.Extractor {
+	return article.NewExtractor(article.WithContainerSelector(c.ContainerSelector))
+}
+
+// NewBank creates a wordBank.Bank with the word bank settings of the configuration.
+//
+// Returns:
+//   - *wordBank.Bank: The configured Bank.
+func (c Config) NewBank() *wordBank.Bank {
+	return wordBank.NewBank(
+		wordBank.WithURL(c.WordBankURL),
+		wordBank.WithLanguageURLs(c.WordBankURLs),
+		wordBank.WithStopwordsRemoved(c.RemoveStopwords),
+	)
+}
+
+// NewLogger creates a slog.Logger with the logging settings of the configuration.
+// The `quiet` and `verbose` settings override `log_level`.
+//
+// Parameters:
+//   - w: The writer to write the log messages to.
+//
+// Returns:
+//   - *slog.Logger: The configured logger.
+func (c Config) NewLogger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.LogLevel)) // Validated by Validate
+	if c.Quiet {
+		level = slog.LevelError
+	} else if c.Verbose {
+		level = slog.LevelDebug
 	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if c.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// SearchPaths returns the directories that are searched for a "config.yaml" file, in order of priority:
+// the current directory, the user configuration directory (e.g. ~/.config/firefly) and /etc/firefly.
+//
+// Returns:
+//   - []string: The directories to search.
+func SearchPaths() []string {
+	paths := []string{"."}
+	if userConfigDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(userConfigDir, "firefly"))
+	}
+	return append(paths, "/etc/firefly")
+}
+
+// Validate checks that the configuration values are usable, and reports all problems at once.
+//
+// Returns:
+//   - error: An error listing every invalid setting, or nil if the configuration is valid.
+func (c Config) Validate() error {
+	var problems []error
+	check := func(valid bool, format string, args ...interface{}) {
+		if !valid {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.TopResults > 0, "top_results must be greater than 0, got %v", c.TopResults)
+	check(len(c.Input) > 0 || c.SourceURLFileName != "", "source_url_filename must be set when input is empty")
+	check(slices.Contains(OutputFormats, c.OutputFormat), "output_format must be one of %v, got %q", OutputFormats, c.OutputFormat)
+	check(slices.Contains(TrendGranularities, c.TrendsGranularity), "trends_granularity must be one of %v, got %q", TrendGranularities, c.TrendsGranularity)
+	check(c.WordBankURL != "", "word_bank_url must be set")
+	for language, url := range c.WordBankURLs {
+		check(url != "", "word_bank_urls.%v must not be empty", language)
+	}
+	check(strings.TrimSpace(c.ContainerSelector) != "", "container_selector must be set")
+	check(c.RequestsPerSecond > 0, "requests_per_second must be greater than 0, got %v", c.RequestsPerSecond)
+	check(c.BurstSize > 0, "burst_size must be greater than 0, got %v", c.BurstSize)
+	check(c.MaxConcurrentRequests > 0, "max_concurrent_requests must be greater than 0, got %v", c.MaxConcurrentRequests)
+	check(c.MaxRetries >= 0, "max_retries must not be negative, got %v", c.MaxRetries)
+	check(c.MaxRedirects >= 0, "max_redirects must not be negative, got %v", c.MaxRedirects)
+	if !c.NoCache {
+		check(c.CacheDir != "", "cache_dir must be set unless no_cache is set")
+		check(c.CacheTTL > 0, "cache_ttl must be greater than 0, got %v", c.CacheTTL)
+		check(c.CacheMaxSize > 0, "cache_max_size must be greater than 0, got %v", c.CacheMaxSize)
+	}
+	if c.Checkpoint != "" {
+		check(c.CheckpointInterval > 0, "checkpoint_interval must be greater than 0, got %v", c.CheckpointInterval)
+	}
+	check(!c.Resume || c.Checkpoint != "", "checkpoint must be set to resume")
+	check(c.ListenAddress != "", "listen_address must be set")
+	check(c.MaxRunningJobs > 0, "max_running_jobs must be greater than 0, got %v", c.MaxRunningJobs)
+	check(c.JobHistory > 0, "job_history must be greater than 0, got %v", c.JobHistory)
+	check(slices.Contains(LogFormats, c.LogFormat), "log_format must be one of %v, got %q", LogFormats, c.LogFormat)
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "log_level must be one of debug, info, warn or error, got %q", c.LogLevel)
+	check(!(c.Quiet && c.Verbose), "quiet and verbose cannot both be set")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// envName returns the name of the environment variable that overrides the given configuration key.
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(key)
 }
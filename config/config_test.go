@@ -1,9 +1,22 @@
 package config
 
 import (
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/checkpoint"
+	"firefly-assignment/display"
+	"firefly-assignment/jobManager"
+	"firefly-assignment/network"
+	"firefly-assignment/trends"
+	"firefly-assignment/wordBank"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 	"golang.org/x/time/rate"
 )
 
@@ -21,13 +34,29 @@ func TestLoadConfig(t *testing.T) {
 			expectedConfig: Config{
 				TopResults:            10,
 				SourceURLFileName:     "endg-urls",
+				Input:                 []string{},
+				PagesDir:              "pages",
+				OutputFormat:          "json",
+				TrendsGranularity:     "day",
 				WordBankURL:           "https://raw.githubusercontent.com/dwyl/english-words/master/words.txt",
+				WordBankURLs:          map[string]string{},
+				RemoveStopwords:       false,
 				ContainerSelector:     ".caas-body",
 				RequestsPerSecond:     rate.Limit(20),
 				BurstSize:             20,
 				MaxConcurrentRequests: 20,
 				MaxRetries:            3,
 				MaxRedirects:          5,
+				CacheDir:              "cache",
+				CacheTTL:              24 * time.Hour,
+				CacheMaxSize:          500,
+				CheckpointInterval:    5 * time.Second,
+				ListenAddress:         ":8080",
+				MaxRunningJobs:        2,
+				JobHistory:            100,
+				Progress:              true,
+				LogFormat:             "text",
+				LogLevel:              "info",
 			},
 			shouldUseDefault: true,
 		},
@@ -36,18 +65,449 @@ func TestLoadConfig(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			// If there's no config, ensure viper falls back to defaults
-			viper.SetConfigFile("") // This disables reading config from file
-
-			// Load the configuration
-			LoadConfig()
+			// Load the configuration. There is no config file in the package directory,
+			// so the defaults are used.
+			got, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
 
 			// Check if the config matches the expected values
-			got := AppConfig
 			want := tt.expectedConfig
-			if got != want {
+			if !reflect.DeepEqual(got, want) {
 				t.Errorf("Expected config: %+v, but got: %+v", want, got)
 			}
 		})
 	}
 }
+
+func TestDefaultsMatchPackages(t *testing.T) {
+	defaults, err := NewLoader("firefly").Load()
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{name: "output_format values", value: OutputFormats, expected: display.Formats},
+		{name: "trends_granularity values", value: TrendGranularities, expected: trends.Granularities},
+		{name: "trends_granularity", value: defaults.TrendsGranularity, expected: trends.Day},
+		{name: "word_bank_url", value: defaults.WordBankURL, expected: wordBank.DefaultURL},
+		{name: "container_selector", value: defaults.ContainerSelector, expected: article.DefaultContainerSelector},
+		{name: "max_retries", value: defaults.MaxRetries, expected: network.DefaultMaxRetries},
+		{name: "max_redirects", value: defaults.MaxRedirects, expected: network.DefaultMaxRedirects},
+		{name: "cache_ttl", value: defaults.CacheTTL, expected: network.DefaultCacheTTL},
+		{name: "cache_max_size", value: defaults.CacheMaxSize, expected: network.DefaultCacheMaxSize >> 20},
+		{name: "checkpoint_interval", value: defaults.CheckpointInterval, expected: checkpoint.DefaultFlushInterval},
+		{name: "max_running_jobs", value: defaults.MaxRunningJobs, expected: jobManager.DefaultMaxRunningJobs},
+		{name: "job_history", value: defaults.JobHistory, expected: jobManager.DefaultHistorySize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.value, tt.expected) {
+				t.Errorf("expected the default of the package %v, got %v", tt.expected, tt.value)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		env           map[string]string
+		expectedTop   int
+		expectedInput []string
+	}{
+		{
+			name:          "Flags override defaults",
+			args:          []string{"--top-results", "5", "-i", "urls.txt", "-i", "sitemap.xml"},
+			expectedTop:   5,
+			expectedInput: []string{"urls.txt", "sitemap.xml"},
+		},
+		{
+			name:          "Environment variables override defaults",
+			env:           map[string]string{"FIREFLY_TOP_RESULTS": "7"},
+			expectedTop:   7,
+			expectedInput: []string{},
+		},
+		{
+			name:          "Flags override environment variables",
+			args:          []string{"-n", "3"},
+			env:           map[string]string{"FIREFLY_TOP_RESULTS": "7", "FIREFLY_INPUT": "-,feed.xml"},
+			expectedTop:   3,
+			expectedInput: []string{"-", "feed.xml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			loader := NewLoader("firefly")
+			if _, err := loader.ParseFlags(tt.args); err != nil {
+				t.Fatalf("unexpected error parsing flags: %v", err)
+			}
+			got, err := loader.Load()
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+
+			if got.TopResults != tt.expectedTop {
+				t.Errorf("expected top_results %v, got %v", tt.expectedTop, got.TopResults)
+			}
+			if !reflect.DeepEqual(got.Input, tt.expectedInput) {
+				t.Errorf("expected input %q, got %q", tt.expectedInput, got.Input)
+			}
+
+			// The settings snapshot reflects the same precedence.
+			if settings := loader.Settings(); settings["top_results"] != tt.expectedTop {
+				t.Errorf("expected top_results setting %v, got %v", tt.expectedTop, settings["top_results"])
+			}
+		})
+	}
+}
+
+func TestParseFlagsPositionalArgs(t *testing.T) {
+	args, err := NewLoader("firefly inspect").ParseFlags([]string{"--top-results", "5", "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error parsing flags: %v", err)
+	}
+	if len(args) != 1 || args[0] != "https://example.com" {
+		t.Errorf("expected positional args [https://example.com], got %v", args)
+	}
+}
+
+func TestParseFlagsHelp(t *testing.T) {
+	_, err := NewLoader("firefly").ParseFlags([]string{"--help"})
+	if !errors.Is(err, pflag.ErrHelp) {
+		t.Errorf("expected pflag.ErrHelp, got %v", err)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalidConfig := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalidConfig, []byte("max_concurrent_requests: 0\n"), 0644); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{
+			name: "Missing explicit config file",
+			args: []string{"--config", filepath.Join(dir, "missing.yaml")},
+		},
+		{
+			name: "Invalid value in config file",
+			args: []string{"--config", invalidConfig},
+		},
+		{
+			name: "Config file from environment variable",
+			env:  map[string]string{"FIREFLY_CONFIG": invalidConfig},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			loader := NewLoader("firefly")
+			if _, err := loader.ParseFlags(tt.args); err != nil {
+				t.Fatalf("unexpected error parsing flags: %v", err)
+			}
+			if _, err := loader.Load(); err == nil {
+				t.Errorf("expected error loading config, got nil")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Config{
+		TopResults:            10,
+		SourceURLFileName:     "endg-urls",
+		OutputFormat:          "json",
+		TrendsGranularity:     "week",
+		WordBankURL:           "words.txt",
+		ContainerSelector:     ".caas-body",
+		RequestsPerSecond:     rate.Limit(20),
+		BurstSize:             20,
+		MaxConcurrentRequests: 20,
+		MaxRetries:            3,
+		MaxRedirects:          5,
+		LogFormat:             "text",
+		LogLevel:              "info",
+		NoCache:               true,
+		ListenAddress:         ":8080",
+		MaxRunningJobs:        2,
+		JobHistory:            100,
+	}
+
+	tests := []struct {
+		name             string
+		modify           func(c *Config)
+		expectedProblems []string
+	}{
+		{
+			name:   "Valid config",
+			modify: func(c *Config) {},
+		},
+		{
+			name:             "Zero concurrency",
+			modify:           func(c *Config) { c.MaxConcurrentRequests = 0 },
+			expectedProblems: []string{"max_concurrent_requests"},
+		},
+		{
+			name: "All problems are reported at once",
+			modify: func(c *Config) {
+				c.TopResults = 0
+				c.OutputFormat = "xml"
+				c.TrendsGranularity = "year"
+				c.RequestsPerSecond = 0
+				c.MaxRetries = -1
+			},
+			expectedProblems: []string{"top_results", "output_format", "trends_granularity", "requests_per_second", "max_retries"},
+		},
+		{
+			name: "Invalid logging settings",
+			modify: func(c *Config) {
+				c.LogFormat = "xml"
+				c.LogLevel = "loud"
+				c.Quiet = true
+				c.Verbose = true
+			},
+			expectedProblems: []string{"log_format", "log_level", "quiet and verbose"},
+		},
+		{
+			name: "Invalid cache settings",
+			modify: func(c *Config) {
+				c.NoCache = false
+				c.CacheTTL = 0
+				c.CacheMaxSize = -1
+			},
+			expectedProblems: []string{"cache_dir", "cache_ttl", "cache_max_size"},
+		},
+		{
+			name:             "Resume without checkpoint",
+			modify:           func(c *Config) { c.Resume = true },
+			expectedProblems: []string{"checkpoint must be set"},
+		},
+		{
+			name:             "Empty listen address",
+			modify:           func(c *Config) { c.ListenAddress = "" },
+			expectedProblems: []string{"listen_address"},
+		},
+		{
+			name:             "No running jobs",
+			modify:           func(c *Config) { c.MaxRunningJobs = 0 },
+			expectedProblems: []string{"max_running_jobs"},
+		},
+		{
+			name:             "No job history",
+			modify:           func(c *Config) { c.JobHistory = -1 },
+			expectedProblems: []string{"job_history"},
+		},
+		{
+			name: "Invalid checkpoint interval",
+			modify: func(c *Config) {
+				c.Checkpoint = "checkpoint.ndjson"
+				c.Resume = true
+			},
+			expectedProblems: []string{"checkpoint_interval"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+
+			err := c.Validate()
+			if (err != nil) != (len(tt.expectedProblems) > 0) {
+				t.Fatalf("expected problems: %v, got: %v", tt.expectedProblems, err)
+			}
+			for _, problem := range tt.expectedProblems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("expected error to mention %q, got: %v", problem, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:       "Text at info level",
+			config:     Config{LogFormat: "text", LogLevel: "info"},
+			expected:   []string{"level=INFO msg=info url=https://example.com", "level=WARN msg=warn"},
+			unexpected: []string{"msg=debug"},
+		},
+		{
+			name:     "JSON at debug level",
+			config:   Config{LogFormat: "json", LogLevel: "debug"},
+			expected: []string{`"level":"DEBUG","msg":"debug"`, `"url":"https://example.com"`},
+		},
+		{
+			name:       "Quiet overrides log_level",
+			config:     Config{LogFormat: "text", LogLevel: "debug", Quiet: true},
+			expected:   []string{"msg=error"},
+			unexpected: []string{"msg=debug", "msg=info", "msg=warn"},
+		},
+		{
+			name:     "Verbose overrides log_level",
+			config:   Config{LogFormat: "text", LogLevel: "error", Verbose: true},
+			expected: []string{"msg=debug", "msg=info"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			logger := tt.config.NewLogger(&output)
+			logger.Debug("debug")
+			logger.Info("info", "url", "https://example.com")
+			logger.Warn("warn")
+			logger.Error("error")
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(output.String(), expected) {
+					t.Errorf("expected log to contain %q, got:\n%v", expected, output.String())
+				}
+			}
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(output.String(), unexpected) {
+					t.Errorf("expected log not to contain %q, got:\n%v", unexpected, output.String())
+				}
+			}
+		})
+	}
+}
+
+func TestIndependentLoaders(t *testing.T) {
+	first := NewLoader("first")
+	if _, err := first.ParseFlags([]string{"--top-results", "1", "--container-selector", "article"}); err != nil {
+		t.Fatalf("unexpected error parsing flags: %v", err)
+	}
+	second := NewLoader("second")
+	if _, err := second.ParseFlags([]string{"--top-results", "2"}); err != nil {
+		t.Fatalf("unexpected error parsing flags: %v", err)
+	}
+
+	firstConfig, err := first.Load()
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	secondConfig, err := second.Load()
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	if firstConfig.TopResults != 1 || firstConfig.ContainerSelector != "article" {
+		t.Errorf("unexpected first config: %+v", firstConfig)
+	}
+	if secondConfig.TopResults != 2 || secondConfig.ContainerSelector != ".caas-body" {
+		t.Errorf("unexpected second config: %+v", secondConfig)
+	}
+	if firstConfig.NewExtractor().ContainerSelector() != "article" {
+		t.Errorf("expected extractor to use the configured container selector")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("requests_per_second: 20\n"), 0644); err != nil {
+		t.Fatalf("could not write config file: %v", err)
+	}
+
+	loader := NewLoader("firefly")
+	if _, err := loader.ParseFlags([]string{"--config", path}); err != nil {
+		t.Fatalf("unexpected error parsing flags: %v", err)
+	}
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	changes := make(chan Config, 10)
+	invalid := make(chan error, 10)
+	if !loader.Watch(func(c Config) { changes <- c }, func(err error) { invalid <- err }) {
+		t.Fatal("expected the config file to be watched")
+	}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectInvalid bool
+		expectedRate  rate.Limit
+	}{
+		{name: "Valid change is applied", content: "requests_per_second: 5\n", expectedRate: 5},
+		{name: "Invalid change is rejected", content: "requests_per_second: 5\nmax_concurrent_requests: 0\n", expectInvalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Save the file atomically, like an editor does, so that the watcher never reads a partial file.
+			tmpPath := path + ".tmp"
+			if err := os.WriteFile(tmpPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("could not write config file: %v", err)
+			}
+			if err := os.Rename(tmpPath, path); err != nil {
+				t.Fatalf("could not replace config file: %v", err)
+			}
+
+			select {
+			case c := <-changes:
+				if tt.expectInvalid {
+					t.Fatalf("expected invalid change, got %+v", c)
+				}
+				if c.RequestsPerSecond != tt.expectedRate {
+					t.Errorf("expected requests_per_second %v, got %v", tt.expectedRate, c.RequestsPerSecond)
+				}
+			case err := <-invalid:
+				if !tt.expectInvalid {
+					t.Fatalf("unexpected invalid change: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the config change")
+			}
+
+			// A single save can trigger several events; drain them before the next case.
+			time.Sleep(100 * time.Millisecond)
+			for len(changes) > 0 || len(invalid) > 0 {
+				select {
+				case <-changes:
+				case <-invalid:
+				}
+			}
+		})
+	}
+}
+
+func TestWatchWithoutConfigFile(t *testing.T) {
+	t.Setenv("FIREFLY_CONFIG", "")
+	loader := NewLoader("firefly")
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	if loader.Watch(func(Config) {}, func(error) {}) {
+		t.Error("expected no config file to be watched")
+	}
+}
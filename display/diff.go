@@ -0,0 +1,129 @@
+package display
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"firefly-assignment/wordDiff"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+//go:embed diff.html
+var diffTemplateText string
+
+var diffTemplate = template.Must(template.New("diff").Parse(diffTemplateText))
+
+// diffHeader is the header row of the tabular diff formats.
+var diffHeader = []string{"word", "status", "frequency_a", "frequency_b", "rank_a", "rank_b", "rank_change", "llr"}
+
+// DiffFormatter writes the changes of the words between two runs in a specific output format.
+// Every Formatter returned by NewFormatter is also a DiffFormatter.
+type DiffFormatter interface {
+	FormatDiff(w io.Writer, changes []wordDiff.Change) error
+}
+
+// diffRow returns the fields of a change, in the order of diffHeader. Ranks of words that are
+// not in a run are empty.
+func diffRow(change wordDiff.Change) []string {
+	rank := func(rank int) string {
+		if rank == 0 {
+			return ""
+		}
+		return strconv.Itoa(rank)
+	}
+
+	return []string{
+		change.Word,
+		change.Status,
+		strconv.Itoa(int(change.FrequencyA)),
+		strconv.Itoa(int(change.FrequencyB)),
+		rank(change.RankA),
+		rank(change.RankB),
+		strconv.Itoa(change.RankChange),
+		strconv.FormatFloat(change.LLR, 'f', 2, 64),
+	}
+}
+
+// FormatDiff writes the changes as a pretty-formatted JSON array.
+func (JSONFormatter) FormatDiff(w io.Writer, changes []wordDiff.Change) error {
+	if changes == nil {
+		changes = []wordDiff.Change{}
+	}
+
+	output, err := json.MarshalIndent(changes, "", "    ")
+	if err != nil {
+		return fmt.Errorf("could not convert struct to JSON: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(output))
+	return err
+}
+
+// FormatDiff writes the changes as newline-delimited JSON, one object per line.
+func (NDJSONFormatter) FormatDiff(w io.Writer, changes []wordDiff.Change) error {
+	encoder := json.NewEncoder(w)
+	for _, change := range changes {
+		if err := encoder.Encode(change); err != nil {
+			return fmt.Errorf("could not convert struct to JSON: %w", err)
+		}
+	}
+	return nil
+}
+
+// FormatDiff writes the changes as delimiter-separated values with a header row.
+func (f DelimitedFormatter) FormatDiff(w io.Writer, changes []wordDiff.Change) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = f.Delimiter
+
+	writer.Write(diffHeader)
+	for _, change := range changes {
+		writer.Write(diffRow(change))
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// FormatDiff writes the changes as a Markdown table.
+func (MarkdownFormatter) FormatDiff(w io.Writer, changes []wordDiff.Change) error {
+	var builder strings.Builder
+	builder.WriteString("| Word | Status | Frequency A | Frequency B | Rank A | Rank B | Rank change | LLR |\n")
+	builder.WriteString("| ---- | ------ | ----------: | ----------: | -----: | -----: | ----------: | --: |\n")
+	for _, change := range changes {
+		row := diffRow(change)
+		// Escape pipes, which would otherwise break the table.
+		row[0] = strings.ReplaceAll(row[0], "|", "\\|")
+		fmt.Fprintf(&builder, "| %s |\n", strings.Join(row, " | "))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// FormatDiff writes the changes as a table aligned for terminals.
+func (TableFormatter) FormatDiff(w io.Writer, changes []wordDiff.Change) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(diffHeader, "\t")))
+	for _, change := range changes {
+		fmt.Fprintln(writer, strings.Join(diffRow(change), "\t"))
+	}
+	return writer.Flush()
+}
+
+// FormatDiff writes a self-contained HTML page with a table of the changes.
+func (HTMLFormatter) FormatDiff(w io.Writer, changes []wordDiff.Change) error {
+	rows := make([][]string, 0, len(changes))
+	for _, change := range changes {
+		rows = append(rows, diffRow(change))
+	}
+
+	if err := diffTemplate.Execute(w, rows); err != nil {
+		return fmt.Errorf("could not write HTML diff: %w", err)
+	}
+	return nil
+}
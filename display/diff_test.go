@@ -0,0 +1,84 @@
+package display
+
+import (
+	"firefly-assignment/wordDiff"
+	"strings"
+	"testing"
+)
+
+func TestDiffFormatters(t *testing.T) {
+	changes := []wordDiff.Change{
+		{Word: "apple", Status: wordDiff.StatusRose, FrequencyA: 2, FrequencyB: 8, RankA: 3, RankB: 1, RankChange: 2, LLR: 4.5},
+		{Word: "pie", Status: wordDiff.StatusNew, FrequencyB: 3, RankB: 2, LLR: 4.159},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "json",
+			expected: "[\n    {\n        \"word\": \"apple\",\n        \"status\": \"rose\",\n        \"frequency_a\": 2,\n        \"frequency_b\": 8,\n        \"rank_a\": 3,\n        \"rank_b\": 1,\n        \"rank_change\": 2,\n        \"llr\": 4.5\n    },\n    {\n        \"word\": \"pie\",\n        \"status\": \"new\",\n        \"frequency_a\": 0,\n        \"frequency_b\": 3,\n        \"rank_b\": 2,\n        \"rank_change\": 0,\n        \"llr\": 4.159\n    }\n]\n",
+		},
+		{
+			format:   "ndjson",
+			expected: "{\"word\":\"apple\",\"status\":\"rose\",\"frequency_a\":2,\"frequency_b\":8,\"rank_a\":3,\"rank_b\":1,\"rank_change\":2,\"llr\":4.5}\n{\"word\":\"pie\",\"status\":\"new\",\"frequency_a\":0,\"frequency_b\":3,\"rank_b\":2,\"rank_change\":0,\"llr\":4.159}\n",
+		},
+		{
+			format:   "csv",
+			expected: "word,status,frequency_a,frequency_b,rank_a,rank_b,rank_change,llr\napple,rose,2,8,3,1,2,4.50\npie,new,0,3,,2,0,4.16\n",
+		},
+		{
+			format:   "tsv",
+			expected: "word\tstatus\tfrequency_a\tfrequency_b\trank_a\trank_b\trank_change\tllr\napple\trose\t2\t8\t3\t1\t2\t4.50\npie\tnew\t0\t3\t\t2\t0\t4.16\n",
+		},
+		{
+			format:   "markdown",
+			expected: "| Word | Status | Frequency A | Frequency B | Rank A | Rank B | Rank change | LLR |\n| ---- | ------ | ----------: | ----------: | -----: | -----: | ----------: | --: |\n| apple | rose | 2 | 8 | 3 | 1 | 2 | 4.50 |\n| pie | new | 0 | 3 |  | 2 | 0 | 4.16 |\n",
+		},
+		{
+			format:   "table",
+			expected: "WORD   STATUS  FREQUENCY_A  FREQUENCY_B  RANK_A  RANK_B  RANK_CHANGE  LLR\napple  rose    2            8            3       1       2            4.50\npie    new     0            3                    2       0            4.16\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, _ := NewFormatter(tt.format)
+			var output strings.Builder
+			if err := formatter.(DiffFormatter).FormatDiff(&output, changes); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.String() != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestHTMLDiffFormatter(t *testing.T) {
+	changes := []wordDiff.Change{{Word: "<apple>", Status: wordDiff.StatusVanished, FrequencyA: 4, RankA: 1, LLR: 5.5}}
+
+	var output strings.Builder
+	if err := (HTMLFormatter{}).FormatDiff(&output, changes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"<td>&lt;apple&gt;</td>", `<td class="vanished">vanished</td>`, `<td class="number">5.50</td>`} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected HTML to contain %q", expected)
+		}
+	}
+}
+
+func TestAllFormattersFormatDiffs(t *testing.T) {
+	for _, format := range Formats {
+		formatter, err := NewFormatter(format)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := formatter.(DiffFormatter); !ok {
+			t.Errorf("expected the %v formatter to be a DiffFormatter", format)
+		}
+	}
+}
@@ -25,7 +25,7 @@ func GetPrettyJSON(words []utils.WordFreq) (string, error) {
 	prettyJSON, err := json.MarshalIndent(words, "", "    ")
 
 	if err != nil {
-		return "", fmt.Errorf("[ERROR] - Could not convert struct to JSON - %w", err)
+		return "", fmt.Errorf("could not convert struct to JSON: %w", err)
 	}
 
 	return string(prettyJSON), nil
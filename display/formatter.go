@@ -0,0 +1,126 @@
+package display
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"firefly-assignment/utils"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats lists the names of the supported output formats.
+var Formats = []string{"json", "ndjson", "csv", "tsv", "markdown", "table", "html"}
+
+// Formatter writes a list of word frequencies to a writer in a specific output format.
+type Formatter interface {
+	Format(w io.Writer, words []utils.WordFreq) error
+}
+
+// NewFormatter returns the Formatter for the given output format name.
+//
+// Parameters:
+//   - format: The name of the output format, one of Formats.
+//
+// Returns:
+//   - Formatter: The Formatter for the output format.
+//   - error: An error if the output format is not supported.
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case "json":
+		return JSONFormatter{}, nil
+	case "ndjson":
+		return NDJSONFormatter{}, nil
+	case "csv":
+		return DelimitedFormatter{Delimiter: ','}, nil
+	case "tsv":
+		return DelimitedFormatter{Delimiter: '\t'}, nil
+	case "markdown":
+		return MarkdownFormatter{}, nil
+	case "table":
+		return TableFormatter{}, nil
+	case "html":
+		return HTMLFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, expected one of %v", format, Formats)
+	}
+}
+
+// JSONFormatter writes the word frequencies as a pretty-formatted JSON array.
+type JSONFormatter struct{}
+
+// Format writes the word frequencies as a pretty-formatted JSON array.
+func (JSONFormatter) Format(w io.Writer, words []utils.WordFreq) error {
+	output, err := GetPrettyJSON(words)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, output)
+	return err
+}
+
+// NDJSONFormatter writes the word frequencies as newline-delimited JSON, one object per line.
+type NDJSONFormatter struct{}
+
+// Format writes the word frequencies as newline-delimited JSON, one object per line.
+func (NDJSONFormatter) Format(w io.Writer, words []utils.WordFreq) error {
+	encoder := json.NewEncoder(w)
+	for _, word := range words {
+		if err := encoder.Encode(word); err != nil {
+			return fmt.Errorf("could not convert struct to JSON: %w", err)
+		}
+	}
+	return nil
+}
+
+// DelimitedFormatter writes the word frequencies as delimiter-separated values (CSV or TSV) with a header row.
+type DelimitedFormatter struct {
+	Delimiter rune
+}
+
+// Format writes the word frequencies as delimiter-separated values with a header row.
+func (f DelimitedFormatter) Format(w io.Writer, words []utils.WordFreq) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = f.Delimiter
+
+	writer.Write([]string{"word", "frequency"})
+	for _, word := range words {
+		writer.Write([]string{word.Word, strconv.Itoa(int(word.Frequency))})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// MarkdownFormatter writes the word frequencies as a Markdown table.
+type MarkdownFormatter struct{}
+
+// Format writes the word frequencies as a Markdown table.
+func (MarkdownFormatter) Format(w io.Writer, words []utils.WordFreq) error {
+	var builder strings.Builder
+	builder.WriteString("| Rank | Word | Frequency |\n")
+	builder.WriteString("| ---: | ---- | --------: |\n")
+	for i, word := range words {
+		// Escape pipes, which would otherwise break the table.
+		fmt.Fprintf(&builder, "| %d | %s | %d |\n", i+1, strings.ReplaceAll(word.Word, "|", "\\|"), word.Frequency)
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// TableFormatter writes the word frequencies as a table aligned for terminals.
+type TableFormatter struct{}
+
+// Format writes the word frequencies as a table aligned for terminals.
+func (TableFormatter) Format(w io.Writer, words []utils.WordFreq) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "RANK\tWORD\tFREQUENCY")
+	for i, word := range words {
+		fmt.Fprintf(writer, "%d\t%s\t%d\n", i+1, word.Word, word.Frequency)
+	}
+	return writer.Flush()
+}
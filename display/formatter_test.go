@@ -0,0 +1,100 @@
+package display
+
+import (
+	"firefly-assignment/utils"
+	"strings"
+	"testing"
+)
+
+func TestFormatters(t *testing.T) {
+	words := []utils.WordFreq{
+		{Word: "test", Frequency: 10},
+		{Word: "example", Frequency: 5},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "JSON",
+			format:   "json",
+			expected: "[\n    {\n        \"Word\": \"test\",\n        \"Frequency\": 10\n    },\n    {\n        \"Word\": \"example\",\n        \"Frequency\": 5\n    }\n]\n",
+		},
+		{
+			name:     "NDJSON",
+			format:   "ndjson",
+			expected: "{\"Word\":\"test\",\"Frequency\":10}\n{\"Word\":\"example\",\"Frequency\":5}\n",
+		},
+		{
+			name:     "CSV",
+			format:   "csv",
+			expected: "word,frequency\ntest,10\nexample,5\n",
+		},
+		{
+			name:     "TSV",
+			format:   "tsv",
+			expected: "word\tfrequency\ntest\t10\nexample\t5\n",
+		},
+		{
+			name:     "Markdown",
+			format:   "markdown",
+			expected: "| Rank | Word | Frequency |\n| ---: | ---- | --------: |\n| 1 | test | 10 |\n| 2 | example | 5 |\n",
+		},
+		{
+			name:     "Table",
+			format:   "table",
+			expected: "RANK  WORD     FREQUENCY\n1     test     10\n2     example  5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var output strings.Builder
+			if err := formatter.Format(&output, words); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if output.String() != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestNewFormatterUnsupported(t *testing.T) {
+	if _, err := NewFormatter("xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestFormatterEscaping(t *testing.T) {
+	words := []utils.WordFreq{{Word: "a,b|c", Frequency: 1}}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: "csv", expected: "word,frequency\n\"a,b|c\",1\n"},
+		{format: "markdown", expected: "| Rank | Word | Frequency |\n| ---: | ---- | --------: |\n| 1 | a,b\\|c | 1 |\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, _ := NewFormatter(tt.format)
+			var output strings.Builder
+			if err := formatter.Format(&output, words); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package display
+
+import (
+	_ "embed"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// Dimensions of the bar chart of the HTML report, in pixels.
+const (
+	chartLabelWidth = 140
+	chartBarWidth   = 600
+	chartBarHeight  = 20
+)
+
+// Font sizes of the smallest and largest words of the word cloud of the HTML report, in em.
+const (
+	cloudMinSize = 0.8
+	cloudMaxSize = 3.0
+)
+
+//go:embed report.html
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(reportTemplateText))
+
+// ReportFormatter is a Formatter that can also write a complete run summary, rather than only the top words.
+type ReportFormatter interface {
+	Formatter
+	FormatReport(w io.Writer, summary *runSummary.Summary) error
+}
+
+// HTMLFormatter writes a self-contained HTML report, with the styles and charts inline, so that
+// the report can be shared as a single file and viewed without network access.
+type HTMLFormatter struct{}
+
+// Format writes an HTML report of the word frequencies only.
+func (f HTMLFormatter) Format(w io.Writer, words []utils.WordFreq) error {
+	return f.FormatReport(w, &runSummary.Summary{TopWords: words})
+}
+
+// FormatReport writes an HTML report of the run summary: the totals, a bar chart and a word cloud
+// of the top words, and tables of the top entities, errors, languages, articles and configuration.
+//
+// Parameters:
+//   - w: The writer to write the report to.
+//   - summary: The run summary.
+//
+// Returns:
+//   - error: An error if the report cannot be written.
+func (HTMLFormatter) FormatReport(w io.Writer, summary *runSummary.Summary) error {
+	if err := reportTemplate.Execute(w, newReportData(summary)); err != nil {
+		return fmt.Errorf("could not write HTML report: %w", err)
+	}
+	return nil
+}
+
+// reportBar is a bar of the bar chart.
+type reportBar struct {
+	Word      string
+	Frequency int32
+	Y         int
+	Width     float64
+	LabelX    float64
+}
+
+// reportCloudWord is a word of the word cloud.
+type reportCloudWord struct {
+	Word      string
+	Frequency int32
+	Size      string
+}
+
+// reportData is the data rendered by the report template.
+type reportData struct {
+	Title       string
+	Command     string
+	StartedAt   string
+	Duration    string
+	Totals      runSummary.Totals
+	ChartWidth  int
+	ChartHeight int
+	LabelWidth  int
+	Bars        []reportBar
+	Cloud       []reportCloudWord
+	TopEntities []utils.WordFreq
+	Errors      []reportError
+	Languages   []reportLanguage
+	Articles    []runSummary.Article
+	Config      []reportSetting
+}
+
+// reportError is a row of the error table.
+type reportError struct {
+	Class       string
+	Count       int
+	ExampleURLs []string
+}
+
+// reportLanguage is a row of the language table.
+type reportLanguage struct {
+	Language string
+	Count    int32
+}
+
+// reportSetting is a row of the configuration table.
+type reportSetting struct {
+	Key   string
+	Value interface{}
+}
+
+// newReportData computes the charts and sorted tables of the report from the run summary.
+func newReportData(summary *runSummary.Summary) reportData {
+	data := reportData{
+		Title:       "Word frequency report",
+		Command:     summary.Command,
+		Totals:      summary.Totals,
+		ChartWidth:  chartLabelWidth + chartBarWidth + 60,
+		ChartHeight: len(summary.TopWords) * chartBarHeight,
+		LabelWidth:  chartLabelWidth,
+		TopEntities: summary.TopEntities,
+		Articles:    summary.Articles,
+	}
+	if !summary.StartedAt.IsZero() {
+		data.StartedAt = summary.StartedAt.Format(time.RFC1123)
+		data.Duration = fmt.Sprintf("%.1fs", summary.DurationSeconds)
+	}
+
+	// The words are sorted by frequency, so the first word has the longest bar and the largest font.
+	var maxFrequency, minFrequency int32
+	if len(summary.TopWords) > 0 {
+		maxFrequency = summary.TopWords[0].Frequency
+		minFrequency = summary.TopWords[len(summary.TopWords)-1].Frequency
+	}
+
+	for i, word := range summary.TopWords {
+		width := float64(chartBarWidth) * float64(word.Frequency) / float64(maxFrequency)
+		data.Bars = append(data.Bars, reportBar{
+			Word:      word.Word,
+			Frequency: word.Frequency,
+			Y:         i * chartBarHeight,
+			Width:     width,
+			LabelX:    chartLabelWidth + width,
+		})
+
+		// Scale the font size logarithmically, so that a few very frequent words do not dwarf the others.
+		scale := 1.0
+		if maxFrequency > minFrequency {
+			scale = math.Log(float64(word.Frequency)/float64(minFrequency)) / math.Log(float64(maxFrequency)/float64(minFrequency))
+		}
+		data.Cloud = append(data.Cloud, reportCloudWord{
+			Word:      word.Word,
+			Frequency: word.Frequency,
+			Size:      fmt.Sprintf("%.2f", cloudMinSize+scale*(cloudMaxSize-cloudMinSize)),
+		})
+	}
+	// Show the word cloud in alphabetical order, so that its layout does not mirror the bar chart.
+	sort.Slice(data.Cloud, func(i, j int) bool { return data.Cloud[i].Word < data.Cloud[j].Word })
+
+	for class, errorCount := range summary.Errors {
+		data.Errors = append(data.Errors, reportError{Class: class, Count: errorCount.Count, ExampleURLs: errorCount.ExampleURLs})
+	}
+	sort.Slice(data.Errors, func(i, j int) bool {
+		if data.Errors[i].Count != data.Errors[j].Count {
+			return data.Errors[i].Count > data.Errors[j].Count
+		}
+		return data.Errors[i].Class < data.Errors[j].Class
+	})
+
+	for language, count := range summary.Languages {
+		data.Languages = append(data.Languages, reportLanguage{Language: language, Count: count})
+	}
+	sort.Slice(data.Languages, func(i, j int) bool { return data.Languages[i].Language < data.Languages[j].Language })
+
+	for key, value := range summary.Config {
+		data.Config = append(data.Config, reportSetting{Key: key, Value: value})
+	}
+	sort.Slice(data.Config, func(i, j int) bool { return data.Config[i].Key < data.Config[j].Key })
+
+	return data
+}
@@ -0,0 +1,72 @@
+package display
+
+import (
+	"firefly-assignment/network"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"strings"
+	"testing"
+)
+
+func TestHTMLFormatterReport(t *testing.T) {
+	summary := runSummary.New("firefly run", map[string]interface{}{"top_results": 2})
+	summary.Totals = runSummary.Totals{Total: 3, Processed: 2, Errored: 1}
+	summary.TopWords = []utils.WordFreq{{Word: "test", Frequency: 10}, {Word: "<b>example</b>", Frequency: 5}}
+	summary.TopEntities = []utils.WordFreq{{Word: "Apple", Frequency: 2}}
+	summary.Languages = map[string]int32{"en": 2}
+	summary.AddError("https://example.com/missing", &network.StatusError{StatusCode: 404})
+	summary.AddArticle(runSummary.Article{URL: "https://example.com/a", Language: "en", Words: 12, CountedWords: 8, TopWords: summary.TopWords})
+	summary.Finish()
+
+	var output strings.Builder
+	if err := (HTMLFormatter{}).FormatReport(&output, summary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := output.String()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Bar chart", expected: "<svg class=\"chart\""},
+		{name: "Bar of the top word", expected: "width=\"600\""},
+		{name: "Word cloud", expected: "<div class=\"cloud\">"},
+		{name: "Largest word in the cloud", expected: "font-size: 3.00em"},
+		{name: "Escaped word", expected: "&lt;b&gt;example&lt;/b&gt;"},
+		{name: "Entities", expected: "<td>Apple</td>"},
+		{name: "Errors", expected: "<td>not_found</td>"},
+		{name: "Error examples", expected: "https://example.com/missing"},
+		{name: "Articles", expected: "<td>https://example.com/a</td>"},
+		{name: "Configuration", expected: "<code>top_results</code>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(report, tt.expected) {
+				t.Errorf("expected report to contain %q", tt.expected)
+			}
+		})
+	}
+
+	// The report must be self-contained, without external assets.
+	for _, external := range []string{"<script src", "<link", "<img", "@import"} {
+		if strings.Contains(report, external) {
+			t.Errorf("expected no external assets, found %q", external)
+		}
+	}
+}
+
+func TestHTMLFormatterWords(t *testing.T) {
+	formatter, err := NewFormatter("html")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var output strings.Builder
+	if err := formatter.Format(&output, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output.String(), "No words were counted.") {
+		t.Errorf("expected an empty report, got %q", output.String())
+	}
+}
@@ -0,0 +1,139 @@
+package jobManager
+
+import (
+	"context"
+	"sync"
+)
+
+// Budget is a concurrency limit shared by the running jobs. A job that asks for a slot while others are
+// waiting queues up, and each freed slot goes to the waiting job that holds the fewest slots, so that
+// every running job gets a fair share of the budget however many URLs it has. Between jobs that hold
+// as many slots, the job with the highest priority wins, and then the job that has waited the longest.
+type Budget struct {
+	mutex   sync.Mutex
+	limit   int
+	inUse   int
+	held    map[string]int
+	waiters []*waiter
+}
+
+// waiter is a request for a slot of the budget that is not granted yet.
+type waiter struct {
+	job      string
+	priority int
+	ready    chan struct{}
+	granted  bool
+}
+
+// NewBudget creates a Budget with the given number of slots.
+//
+// Parameters:
+//   - limit: The number of slots, that is the maximum number of requests at once over all jobs.
+//
+// Returns:
+//   - *Budget: The Budget.
+func NewBudget(limit int) *Budget {
+	return &Budget{limit: limit, held: make(map[string]int)}
+}
+
+// Acquire blocks until the job is granted a slot of the budget, or the context is done.
+//
+// Parameters:
+//   - ctx: The context of the job.
+//   - job: The ID of the job.
+//   - priority: The priority of the job.
+//
+// Returns:
+//   - error: The error of the context if it is done before a slot is granted.
+func (b *Budget) Acquire(ctx context.Context, job string, priority int) error {
+	b.mutex.Lock()
+	if b.inUse < b.limit && len(b.waiters) == 0 {
+		b.grant(job)
+		b.mutex.Unlock()
+		return nil
+	}
+	w := &waiter{job: job, priority: priority, ready: make(chan struct{})}
+	b.waiters = append(b.waiters, w)
+	b.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		b.mutex.Lock()
+		granted := w.granted
+		if !granted {
+			b.remove(w)
+		}
+		b.mutex.Unlock()
+
+		// The slot may have been granted while the context was done; hand it over to the next job.
+		if granted {
+			b.Release(job)
+		}
+		return ctx.Err()
+	}
+}
+
+// Release returns a slot of the job to the budget, and grants it to the next waiting job, if any.
+//
+// Parameters:
+//   - job: The ID of the job.
+func (b *Budget) Release(job string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.inUse--
+	if b.held[job]--; b.held[job] == 0 {
+		delete(b.held, job)
+	}
+	b.dispatch()
+}
+
+// InUse returns the number of slots in use.
+func (b *Budget) InUse() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.inUse
+}
+
+// Limit returns the number of slots of the budget.
+func (b *Budget) Limit() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.limit
+}
+
+// grant gives a slot to the job.
+func (b *Budget) grant(job string) {
+	b.inUse++
+	b.held[job]++
+}
+
+// dispatch grants the free slots to the waiting jobs, fairest first.
+func (b *Budget) dispatch() {
+	for b.inUse < b.limit && len(b.waiters) > 0 {
+		next := b.waiters[0]
+		for _, w := range b.waiters[1:] {
+			if held, nextHeld := b.held[w.job], b.held[next.job]; held < nextHeld || (held == nextHeld && w.priority > next.priority) {
+				next = w
+			}
+		}
+		b.remove(next)
+		b.grant(next.job)
+		next.granted = true
+		close(next.ready)
+	}
+}
+
+// remove removes the waiter from the queue, keeping the order of the others.
+func (b *Budget) remove(w *waiter) {
+	for i, other := range b.waiters {
+		if other == w {
+			b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+			return
+		}
+	}
+}
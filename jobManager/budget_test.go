@@ -0,0 +1,105 @@
+package jobManager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync asks for a slot of the budget in the background, and waits until the request is queued.
+// The returned channel receives the ID of the job once the slot is granted.
+func acquireAsync(t *testing.T, b *Budget, granted chan string, job string, priority int) {
+	b.mutex.Lock()
+	waiting := len(b.waiters)
+	b.mutex.Unlock()
+
+	go func() {
+		if err := b.Acquire(context.Background(), job, priority); err == nil {
+			granted <- job
+		}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		b.mutex.Lock()
+		queued := len(b.waiters) > waiting
+		b.mutex.Unlock()
+		if queued {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected job %v to wait for a slot", job)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBudgetFairness(t *testing.T) {
+	tests := []struct {
+		name          string
+		waiters       []string
+		priorities    map[string]int
+		expectedOrder []string
+	}{
+		{
+			// Job "a" holds both slots, so the first freed slot goes to "b" although "a" asked before it.
+			name:          "Jobs holding fewer slots go first",
+			waiters:       []string{"a", "b"},
+			expectedOrder: []string{"b", "a"},
+		},
+		{
+			name:          "Higher priority breaks ties",
+			waiters:       []string{"b", "c"},
+			priorities:    map[string]int{"c": 5},
+			expectedOrder: []string{"c", "b"},
+		},
+		{
+			name:          "Earlier request breaks ties",
+			waiters:       []string{"c", "b"},
+			expectedOrder: []string{"c", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBudget(2)
+			for i := 0; i < 2; i++ {
+				if err := b.Acquire(context.Background(), "a", 0); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			granted := make(chan string, len(tt.waiters))
+			for _, job := range tt.waiters {
+				acquireAsync(t, b, granted, job, tt.priorities[job])
+			}
+
+			for _, expected := range tt.expectedOrder {
+				b.Release("a")
+				if job := <-granted; job != expected {
+					t.Fatalf("expected the slot to go to %v, got %v", expected, job)
+				}
+			}
+			if b.InUse() != 2 {
+				t.Errorf("expected 2 slots in use, got %v", b.InUse())
+			}
+		})
+	}
+}
+
+func TestBudgetAcquireCancelled(t *testing.T) {
+	b := NewBudget(1)
+	b.Acquire(context.Background(), "a", 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Acquire(ctx, "b", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error of the context, got %v", err)
+	}
+
+	b.Release("a")
+	if b.InUse() != 0 || len(b.waiters) != 0 || len(b.held) != 0 {
+		t.Errorf("expected an idle budget, got %v in use, %v waiters, %v held", b.InUse(), len(b.waiters), b.held)
+	}
+}
@@ -0,0 +1,197 @@
+package jobManager
+
+import (
+	"context"
+	"firefly-assignment/pipeline"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/semaphore"
+	"sync"
+	"time"
+)
+
+// Statuses of a job.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+)
+
+// Spec is the specification of a job: the URLs to fetch, the documents submitted inline as HTML or
+// plain text, and the settings of the job. Zero settings take the defaults of the Manager.
+type Spec struct {
+	URLs                  []string `json:"urls"`
+	HTML                  []string `json:"html"`
+	Text                  []string `json:"text"`
+	Priority              int      `json:"priority"`
+	ContainerSelector     string   `json:"container_selector"`
+	MaxConcurrentRequests int      `json:"max_concurrent_requests"`
+	TopResults            int      `json:"top_results"`
+}
+
+// total returns the number of documents of the job.
+func (s Spec) total() int {
+	return len(s.URLs) + len(s.HTML) + len(s.Text)
+}
+
+// JobStatus is the progress of a job.
+type JobStatus struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Errored     int        `json:"errored"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// Job holds the isolated state of a job: its settings, its progress, its counts and its summary.
+type Job struct {
+	id         string
+	number     int
+	spec       Spec
+	total      int
+	topResults int
+	pipeline   *pipeline.Pipeline
+	semaphore  *semaphore.Semaphore
+	ctx        context.Context
+	cancel     context.CancelFunc
+	index      int              // Index of the job in the queue of the Manager
+	totals     *pipeline.Totals // Safe to use concurrently
+
+	mutex       sync.Mutex
+	status      string
+	processed   int
+	errored     int
+	submittedAt time.Time
+	startedAt   time.Time
+	finishedAt  time.Time
+	summary     *runSummary.Summary
+}
+
+// newJob creates a queued job.
+func newJob(id string, number int, spec Spec, settings map[string]interface{}) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Job{
+		id:          id,
+		number:      number,
+		spec:        spec,
+		total:       spec.total(),
+		ctx:         ctx,
+		cancel:      cancel,
+		totals:      pipeline.NewTotals(),
+		status:      StatusQueued,
+		submittedAt: time.Now(),
+		summary:     runSummary.New("serve", settings),
+	}
+}
+
+// ID returns the ID of the job.
+func (j *Job) ID() string {
+	return j.id
+}
+
+// TopResults returns the number of top results of the job, unless the request for its results sets another.
+func (j *Job) TopResults() int {
+	return j.topResults
+}
+
+// Status returns the progress of the job.
+//
+// Returns:
+//   - JobStatus: The progress of the job.
+func (j *Job) Status() JobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	status := JobStatus{
+		ID:          j.id,
+		Status:      j.status,
+		Priority:    j.spec.Priority,
+		Total:       j.total,
+		Processed:   j.processed,
+		Errored:     j.errored,
+		SubmittedAt: j.submittedAt,
+	}
+	if !j.startedAt.IsZero() {
+		startedAt := j.startedAt
+		status.StartedAt = &startedAt
+	}
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		status.FinishedAt = &finishedAt
+	}
+	return status
+}
+
+// Finished reports whether the job is done or cancelled, so that its results are final.
+func (j *Job) Finished() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.status == StatusDone || j.status == StatusCancelled
+}
+
+// WriteResults completes a copy of the summary of the job with its languages and its top n words, entities and
+// TF-IDF scores, and hands it over to the given writer. The job is only locked while the copy is made, not while
+// it is written, so that a slow client does not hold up the job.
+//
+// Parameters:
+//   - n: The number of top results.
+//   - write: The function that writes the summary.
+//
+// Returns:
+//   - error: The error of the writer.
+func (j *Job) WriteResults(n int, write func(summary *runSummary.Summary) error) error {
+	j.mutex.Lock()
+	results := j.summary.Copy()
+	j.mutex.Unlock()
+
+	j.totals.Top(n, results)
+	return write(results)
+}
+
+// start marks the job as running.
+func (j *Job) start() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.status = StatusRunning
+	j.startedAt = time.Now()
+}
+
+// addArticle merges the counts of an article of the job into the totals of the job,
+// and adds the results of the article to the summary of the job.
+func (j *Job) addArticle(result pipeline.Result) {
+	j.summary.AddArticle(j.totals.Add(result))
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.processed++
+}
+
+// addError counts the error of a document of the job.
+func (j *Job) addError(name string, err error) {
+	j.summary.AddError(name, err)
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.errored++
+}
+
+// finish marks the job as done, or as cancelled if it was cancelled, and completes its summary with its totals.
+func (j *Job) finish() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.summary.Totals = runSummary.Totals{Total: j.total, Processed: j.processed, Errored: j.errored}
+	j.summary.Finish()
+	j.status = StatusDone
+	if j.ctx.Err() != nil {
+		j.status = StatusCancelled
+	}
+	j.finishedAt = j.summary.FinishedAt
+	j.cancel()
+}
@@ -0,0 +1,77 @@
+package jobManager
+
+import (
+	"errors"
+	"firefly-assignment/pipeline"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"reflect"
+	"testing"
+)
+
+func TestJob(t *testing.T) {
+	job := newJob("1", 1, Spec{URLs: []string{"https://example.com/a", "https://example.com/missing"}, Text: []string{"phone"}}, nil)
+	if status := job.Status(); status.Status != StatusQueued || status.Total != 3 || status.StartedAt != nil {
+		t.Errorf("expected a queued job with 3 documents, got %+v", status)
+	}
+
+	job.start()
+	job.addArticle(pipeline.Result{URL: "https://example.com/a", Language: "en", Words: 3,
+		Counts: utils.WordFrequencyMap{"apple": 2, "phone": 1}, Entities: utils.WordFrequencyMap{"Apple": 1}})
+	job.addArticle(pipeline.Result{URL: "text#1", Language: "de", Words: 1, Counts: utils.WordFrequencyMap{"phone": 1}})
+
+	if status := job.Status(); status.Status != StatusRunning || status.Processed != 2 || status.FinishedAt != nil {
+		t.Errorf("expected a running job with 2 processed documents, got %+v", status)
+	}
+
+	job.addError("https://example.com/missing", errors.New("failed"))
+	job.finish()
+
+	if !job.Finished() {
+		t.Error("expected the job to be finished")
+	}
+	status := job.Status()
+	if status.Status != StatusDone || status.Total != 3 || status.Processed != 2 || status.Errored != 1 || status.FinishedAt == nil {
+		t.Errorf("expected a finished job, got %+v", status)
+	}
+
+	err := job.WriteResults(1, func(summary *runSummary.Summary) error {
+		if !reflect.DeepEqual(summary.TopWords, []utils.WordFreq{{Word: "apple", Frequency: 2}}) {
+			t.Errorf("expected the top word, got %v", summary.TopWords)
+		}
+		if !reflect.DeepEqual(summary.Languages, map[string]int32{"en": 1, "de": 1}) {
+			t.Errorf("expected the languages of the articles, got %v", summary.Languages)
+		}
+		if summary.Totals != (runSummary.Totals{Total: 3, Processed: 2, Errored: 1}) || summary.Errors[runSummary.ClassOther].Count != 1 {
+			t.Errorf("expected the totals and errors of the job, got %+v %v", summary.Totals, summary.Errors)
+		}
+		if len(summary.TopTFIDF) != 1 || summary.TopTFIDF[0].Word != "apple" {
+			t.Errorf("expected the top word by TF-IDF, got %v", summary.TopTFIDF)
+		}
+		// The job is not locked while its results are written.
+		if job.Status().Status != StatusDone {
+			t.Error("expected the status of the job while its results are written")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Each request writes its own copy, with its own number of top results.
+	job.WriteResults(2, func(summary *runSummary.Summary) error { return nil })
+	if job.summary.TopWords != nil || job.summary.TopEntities != nil || job.summary.TopTFIDF != nil {
+		t.Errorf("expected the summary of the job to be left as is, got %+v", job.summary)
+	}
+}
+
+func TestJobCancelled(t *testing.T) {
+	job := newJob("1", 1, Spec{URLs: []string{"https://example.com/a"}}, nil)
+	job.start()
+	job.cancel()
+	job.finish()
+
+	if status := job.Status(); status.Status != StatusCancelled || status.Processed != 0 {
+		t.Errorf("expected a cancelled job, got %+v", status)
+	}
+}
@@ -0,0 +1,414 @@
+/*
+Package jobManager runs several jobs at once in a long-running process, such as the API server, each with
+its own configuration and isolated state. Jobs are queued by priority, and up to a maximum number of them
+run at once; they share the rate limit and a global concurrency budget that is split fairly between them
+(see Budget). Jobs can be cancelled, and the last finished jobs are kept as history, with their results.
+*/
+package jobManager
+
+import (
+	"container/heap"
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/metrics"
+	"firefly-assignment/network"
+	"firefly-assignment/pipeline"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/semaphore"
+	"firefly-assignment/utils"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults of the Manager.
+const (
+	DefaultTopResults            = 10
+	DefaultMaxConcurrentRequests = 20
+	DefaultMaxRunningJobs        = 2
+	DefaultHistorySize           = 100
+)
+
+var (
+	// ErrJobNotFound is returned for an unknown job, or a job that is no longer in the history.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling a job that is already done or cancelled.
+	ErrJobFinished = errors.New("job already finished")
+	// ErrInvalidSpec is returned when submitting a job without documents or with invalid settings.
+	ErrInvalidSpec = errors.New("invalid job")
+)
+
+// Manager queues, runs and keeps track of jobs.
+type Manager struct {
+	fetcher        *network.Fetcher
+	extractor      *article.Extractor
+	wordBanks      utils.LanguageWordBanks
+	pipeline       *pipeline.Pipeline // Pipeline of the jobs that do not set their own container selector
+	limiter        *rate.Limiter
+	budget         *Budget
+	maxRunningJobs int
+	historySize    int
+	topResults     int
+	settings       map[string]interface{}
+	metrics        *metrics.Metrics
+
+	mutex   sync.Mutex
+	jobs    map[string]*Job
+	queue   jobQueue
+	running int
+	history []*Job // Finished jobs, oldest first
+	nextID  int
+	wg      sync.WaitGroup
+}
+
+// Option configures a Manager.
+type Option func(m *Manager)
+
+// WithLimiter sets the rate limiter that the URLs of all jobs share. By default, the URLs are not rate limited.
+func WithLimiter(limiter *rate.Limiter) Option {
+	return func(m *Manager) { m.limiter = limiter }
+}
+
+// WithMaxConcurrentRequests sets the global concurrency budget: the maximum number of URLs that are fetched at once over all jobs.
+func WithMaxConcurrentRequests(maxConcurrentRequests int) Option {
+	return func(m *Manager) { m.budget = NewBudget(maxConcurrentRequests) }
+}
+
+// WithMaxRunningJobs sets the maximum number of jobs that run at once. Other jobs wait in the queue.
+func WithMaxRunningJobs(maxRunningJobs int) Option {
+	return func(m *Manager) { m.maxRunningJobs = maxRunningJobs }
+}
+
+// WithHistorySize sets the number of finished jobs that are kept, with their results.
+func WithHistorySize(historySize int) Option {
+	return func(m *Manager) { m.historySize = historySize }
+}
+
+// WithTopResults sets the number of top results of the jobs that do not set their own.
+func WithTopResults(topResults int) Option {
+	return func(m *Manager) { m.topResults = topResults }
+}
+
+// WithSettings sets the snapshot of the configuration settings that is included in the summary of each job.
+func WithSettings(settings map[string]interface{}) Option {
+	return func(m *Manager) { m.settings = settings }
+}
+
+// WithMetrics sets the metrics that count the URLs of the jobs and time their extraction. By default, there are no metrics.
+func WithMetrics(metrics *metrics.Metrics) Option {
+	return func(m *Manager) { m.metrics = metrics }
+}
+
+// New creates a Manager with the default settings, overridden by the given options.
+//
+// Parameters:
+//   - fetcher: The fetcher of the URLs of the jobs.
+//   - extractor: The extractor of the articles of the jobs that do not set their own container selector.
+//   - wordBanks: The word banks of valid words, keyed by language.
+//   - opts: The options to apply to the Manager.
+//
+// Returns:
+//   - *Manager: The configured Manager.
+func New(fetcher *network.Fetcher, extractor *article.Extractor, wordBanks utils.LanguageWordBanks, opts ...Option) *Manager {
+	m := &Manager{
+		fetcher:        fetcher,
+		extractor:      extractor,
+		wordBanks:      wordBanks,
+		pipeline:       pipeline.New(extractor, wordBanks),
+		limiter:        rate.NewLimiter(rate.Inf, 0),
+		budget:         NewBudget(DefaultMaxConcurrentRequests),
+		maxRunningJobs: DefaultMaxRunningJobs,
+		historySize:    DefaultHistorySize,
+		topResults:     DefaultTopResults,
+		jobs:           make(map[string]*Job),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Submit queues a job, and starts it right away if fewer than the maximum number of jobs are running.
+//
+// Parameters:
+//   - spec: The specification of the job.
+//
+// Returns:
+//   - *Job: The job.
+//   - error: An error matching ErrInvalidSpec if the job has no documents or invalid settings.
+func (m *Manager) Submit(spec Spec) (*Job, error) {
+	if spec.total() == 0 {
+		return nil, fmt.Errorf("%w: no URLs or documents", ErrInvalidSpec)
+	}
+	if spec.MaxConcurrentRequests < 0 || spec.TopResults < 0 {
+		return nil, fmt.Errorf("%w: max_concurrent_requests and top_results must not be negative", ErrInvalidSpec)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	job := newJob(strconv.Itoa(m.nextID), m.nextID, spec, m.settings)
+	job.topResults = m.topResults
+	if spec.TopResults > 0 {
+		job.topResults = spec.TopResults
+	}
+	job.pipeline = m.pipeline
+	if spec.ContainerSelector != "" {
+		job.pipeline = pipeline.New(article.NewExtractor(article.WithContainerSelector(spec.ContainerSelector)), m.wordBanks)
+	}
+	if spec.MaxConcurrentRequests > 0 {
+		job.semaphore = semaphore.New(spec.MaxConcurrentRequests)
+	}
+
+	m.jobs[job.id] = job
+	heap.Push(&m.queue, job)
+	slog.Info("Queued job", "job", job.id, "priority", spec.Priority, "urls", len(spec.URLs), "documents", len(spec.HTML)+len(spec.Text))
+	m.schedule()
+	return job, nil
+}
+
+// Job returns the job with the given ID.
+//
+// Parameters:
+//   - id: The ID of the job.
+//
+// Returns:
+//   - *Job: The job.
+//   - bool: True if the job is queued, running, or in the history.
+func (m *Manager) Job(id string) (*Job, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, found := m.jobs[id]
+	return job, found
+}
+
+// Jobs returns the status of the queued, running and finished jobs, in order of submission.
+//
+// Returns:
+//   - []JobStatus: The status of each job.
+func (m *Manager) Jobs() []JobStatus {
+	m.mutex.Lock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mutex.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].number < jobs[j].number })
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		statuses = append(statuses, job.Status())
+	}
+	return statuses
+}
+
+// Cancel cancels a job. A queued job is finished right away; a running job stops fetching URLs,
+// and is finished once the URLs in progress are done. The results counted so far are kept.
+//
+// Parameters:
+//   - id: The ID of the job.
+//
+// Returns:
+//   - error: ErrJobNotFound if the job is unknown, or ErrJobFinished if it is already finished.
+func (m *Manager) Cancel(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, found := m.jobs[id]
+	if !found {
+		return ErrJobNotFound
+	}
+	if job.Finished() {
+		return ErrJobFinished
+	}
+
+	job.cancel()
+	if job.Status().Status == StatusQueued {
+		heap.Remove(&m.queue, job.index)
+		job.finish()
+		m.addHistory(job)
+	}
+	slog.Info("Cancelled job", "job", id)
+	return nil
+}
+
+// Analyze counts the words of a single article right away, outside of the queue.
+//
+// Parameters:
+//   - content: The article.
+//
+// Returns:
+//   - *Job: The finished job of the article, which is not kept by the Manager.
+func (m *Manager) Analyze(content article.Article) *Job {
+	job := newJob("", 0, Spec{}, m.settings)
+	job.total = 1
+	job.topResults = m.topResults
+	job.addArticle(m.pipeline.Count("document", content))
+	job.finish()
+	return job
+}
+
+// Extractor returns the default extractor of the Manager.
+func (m *Manager) Extractor() *article.Extractor {
+	return m.extractor
+}
+
+// Wait waits until all queued and running jobs are finished.
+func (m *Manager) Wait() {
+	// A finishing job starts the next queued job before it is done, so the wait group covers the whole queue.
+	m.wg.Wait()
+}
+
+// schedule starts the queued jobs with the highest priority, up to the maximum number of running jobs.
+// The mutex of the Manager must be held.
+func (m *Manager) schedule() {
+	for m.running < m.maxRunningJobs && m.queue.Len() > 0 {
+		job := heap.Pop(&m.queue).(*Job)
+		m.running++
+		job.start()
+		m.wg.Add(1)
+		go m.run(job)
+	}
+}
+
+// addHistory adds a finished job to the history, and forgets the oldest finished jobs beyond the history size.
+// The mutex of the Manager must be held.
+func (m *Manager) addHistory(job *Job) {
+	m.history = append(m.history, job)
+	for len(m.history) > m.historySize {
+		delete(m.jobs, m.history[0].id)
+		m.history = m.history[1:]
+	}
+}
+
+// run fetches and counts the URLs of a job concurrently, within the shared rate limit, the job's own
+// concurrency limit and the global budget, then counts the inline documents of the job. Once the job is
+// finished, the next queued job is started.
+func (m *Manager) run(job *Job) {
+	defer m.wg.Done()
+
+	var wg sync.WaitGroup
+	for _, url := range job.spec.URLs {
+		if err := m.limiter.Wait(job.ctx); err != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if job.semaphore != nil {
+				job.semaphore.Acquire()
+				defer job.semaphore.Release()
+			}
+			if err := m.budget.Acquire(job.ctx, job.id, job.spec.Priority); err != nil {
+				return
+			}
+			defer m.budget.Release(job.id)
+
+			if err := m.process(job, url); err != nil {
+				slog.Error("Failed to process URL", "job", job.id, "url", url, "class", runSummary.ErrorClass(err), "error", err)
+				job.addError(url, err)
+				if m.metrics != nil {
+					m.metrics.URLErrored(err)
+				}
+				return
+			}
+			if m.metrics != nil {
+				m.metrics.URLProcessed()
+			}
+		}()
+	}
+
+	for i, body := range job.spec.HTML {
+		if job.ctx.Err() != nil {
+			break
+		}
+		name := fmt.Sprintf("html#%d", i+1)
+		content, err := job.pipeline.Extract(body)
+		if err != nil {
+			job.addError(name, err)
+			continue
+		}
+		job.addArticle(job.pipeline.Count(name, content))
+	}
+	for i, text := range job.spec.Text {
+		if job.ctx.Err() != nil {
+			break
+		}
+		name := fmt.Sprintf("text#%d", i+1)
+		job.addArticle(job.pipeline.Count(name, article.FromText(text)))
+	}
+
+	wg.Wait()
+	job.finish()
+	status := job.Status()
+	slog.Info("Finished job", "job", job.id, "status", status.Status, "processed", status.Processed, "errored", status.Errored)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.running--
+	m.addHistory(job)
+	m.schedule()
+}
+
+// process fetches a URL of a job, and counts the words of its article.
+func (m *Manager) process(job *Job, url string) error {
+	body, err := m.fetcher.FetchContent(url)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	content, err := job.pipeline.Extract(body)
+	if m.metrics != nil {
+		m.metrics.ExtractFinished(time.Since(start))
+	}
+	if err != nil {
+		return err
+	}
+	job.addArticle(job.pipeline.Count(url, content))
+	return nil
+}
+
+// jobQueue is a priority queue of jobs: the job with the highest priority first, and the earliest
+// submitted job between jobs of the same priority.
+type jobQueue []*Job
+
+// Len returns the number of queued jobs.
+func (q jobQueue) Len() int { return len(q) }
+
+// Less orders the jobs by priority, then by order of submission.
+func (q jobQueue) Less(i, j int) bool {
+	if q[i].spec.Priority != q[j].spec.Priority {
+		return q[i].spec.Priority > q[j].spec.Priority
+	}
+	return q[i].number < q[j].number
+}
+
+// Swap swaps two jobs in the queue.
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+// Push adds a job to the queue.
+func (q *jobQueue) Push(x interface{}) {
+	job := x.(*Job)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+// Pop removes the last job of the queue.
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	job := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return job
+}
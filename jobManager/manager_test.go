@@ -0,0 +1,249 @@
+package jobManager
+
+import (
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/metrics"
+	"firefly-assignment/network"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// gateClient reports each requested URL, and holds the response until it is released.
+type gateClient struct {
+	started chan string
+	release chan struct{}
+}
+
+func (c *gateClient) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	c.started <- string(req.RequestURI())
+	<-c.release
+	resp.SetStatusCode(http.StatusOK)
+	resp.SetBodyString(`<html><body><div class="caas-body">The apple phone.</div></body></html>`)
+	return nil
+}
+
+func newTestManager(opts ...Option) (*Manager, *gateClient) {
+	client := &gateClient{started: make(chan string, 10), release: make(chan struct{})}
+	fetcher := network.NewFetcher(network.WithHTTPClient(client), network.WithMaxRetries(0))
+	wordBanks := utils.LanguageWordBanks{article.DefaultLanguage: {"apple": {}, "phone": {}}}
+	return New(fetcher, article.NewExtractor(), wordBanks, opts...), client
+}
+
+// expectStarted fails the test unless the next requested URL is the expected one.
+func expectStarted(t *testing.T, client *gateClient, expected string) {
+	select {
+	case url := <-client.started:
+		if url != expected {
+			t.Fatalf("expected %v to be fetched, got %v", expected, url)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected %v to be fetched", expected)
+	}
+}
+
+// expectIdle fails the test if a URL is requested shortly.
+func expectIdle(t *testing.T, client *gateClient) {
+	select {
+	case url := <-client.started:
+		t.Fatalf("expected no URL to be fetched, got %v", url)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestManagerSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxRunningJobs int
+		priorities     []int
+		expectedOrder  []string
+	}{
+		{
+			name:           "Higher priority first, then order of submission",
+			maxRunningJobs: 1,
+			priorities:     []int{0, 0, 5, 5},
+			expectedOrder:  []string{"/1", "/3", "/4", "/2"},
+		},
+		{
+			name:           "Several running jobs",
+			maxRunningJobs: 2,
+			priorities:     []int{0, 0, 1},
+			expectedOrder:  []string{"/1", "/2", "/3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, client := newTestManager(WithMaxRunningJobs(tt.maxRunningJobs))
+			for i, priority := range tt.priorities {
+				if _, err := manager.Submit(Spec{URLs: []string{"/" + strconv.Itoa(i+1)}, Priority: priority}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			// The first jobs run right away, in any order, up to the maximum; the others wait for a running job to finish.
+			expectedRunning := make(map[string]bool)
+			for _, url := range tt.expectedOrder[:tt.maxRunningJobs] {
+				expectedRunning[url] = true
+			}
+			for range expectedRunning {
+				select {
+				case url := <-client.started:
+					if !expectedRunning[url] {
+						t.Fatalf("expected one of %v to be fetched, got %v", expectedRunning, url)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("expected %v to be fetched", expectedRunning)
+				}
+			}
+			for _, url := range tt.expectedOrder[tt.maxRunningJobs:] {
+				expectIdle(t, client)
+				client.release <- struct{}{}
+				expectStarted(t, client, url)
+			}
+			for i := 0; i < tt.maxRunningJobs; i++ {
+				client.release <- struct{}{}
+			}
+			manager.Wait()
+
+			for _, status := range manager.Jobs() {
+				if status.Status != StatusDone || status.Processed != 1 {
+					t.Errorf("expected job %v to be done, got %+v", status.ID, status)
+				}
+			}
+		})
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	manager, client := newTestManager(WithMaxRunningJobs(1))
+	running, _ := manager.Submit(Spec{URLs: []string{"/1"}})
+	queued, _ := manager.Submit(Spec{URLs: []string{"/2"}})
+	expectStarted(t, client, "/1")
+
+	// A queued job is finished right away, and never fetches its URLs.
+	if err := manager.Cancel(queued.ID()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := queued.Status(); status.Status != StatusCancelled || status.StartedAt != nil || status.FinishedAt == nil {
+		t.Errorf("expected a cancelled queued job, got %+v", status)
+	}
+
+	// A running job is finished once its URLs in progress are done, and keeps their results.
+	if err := manager.Cancel(running.ID()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.release <- struct{}{}
+	manager.Wait()
+	expectIdle(t, client)
+	if status := running.Status(); status.Status != StatusCancelled || status.Processed != 1 {
+		t.Errorf("expected a cancelled running job with its processed URL, got %+v", status)
+	}
+
+	tests := []struct {
+		name          string
+		id            string
+		expectedError error
+	}{
+		{name: "Finished job", id: running.ID(), expectedError: ErrJobFinished},
+		{name: "Unknown job", id: "42", expectedError: ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.Cancel(tt.id); !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestManagerHistory(t *testing.T) {
+	manager, _ := newTestManager(WithHistorySize(2))
+	for i := 0; i < 3; i++ {
+		manager.Submit(Spec{Text: []string{"apple"}})
+		manager.Wait()
+	}
+
+	if _, found := manager.Job("1"); found {
+		t.Error("expected the oldest job to be forgotten")
+	}
+	statuses := manager.Jobs()
+	if len(statuses) != 2 || statuses[0].ID != "2" || statuses[1].ID != "3" {
+		t.Errorf("expected the last 2 jobs, got %+v", statuses)
+	}
+}
+
+func TestManagerSubmit(t *testing.T) {
+	tests := []struct {
+		name               string
+		spec               Spec
+		expectedError      error
+		expectedTopResults int
+		expectedWord       string
+	}{
+		{
+			name:               "Default settings",
+			spec:               Spec{HTML: []string{`<div class="caas-body">Apple.</div><main>Phone.</main>`}},
+			expectedTopResults: DefaultTopResults,
+			expectedWord:       "apple",
+		},
+		{
+			name:               "Own settings",
+			spec:               Spec{HTML: []string{`<div class="caas-body">Apple.</div><main>Phone.</main>`}, ContainerSelector: "main", TopResults: 3},
+			expectedTopResults: 3,
+			expectedWord:       "phone",
+		},
+		{name: "No documents", spec: Spec{}, expectedError: ErrInvalidSpec},
+		{name: "Negative setting", spec: Spec{Text: []string{"apple"}, MaxConcurrentRequests: -1}, expectedError: ErrInvalidSpec},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, _ := newTestManager()
+			job, err := manager.Submit(tt.spec)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			manager.Wait()
+
+			if job.TopResults() != tt.expectedTopResults {
+				t.Errorf("expected %v top results, got %v", tt.expectedTopResults, job.TopResults())
+			}
+			job.WriteResults(1, func(summary *runSummary.Summary) error {
+				if len(summary.TopWords) != 1 || summary.TopWords[0].Word != tt.expectedWord {
+					t.Errorf("expected the top word %v, got %v", tt.expectedWord, summary.TopWords)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestManagerMetrics(t *testing.T) {
+	jobMetrics := metrics.New()
+	manager, client := newTestManager(WithMetrics(jobMetrics))
+	manager.Submit(Spec{URLs: []string{"/1"}, Text: []string{"apple"}})
+	expectStarted(t, client, "/1")
+	client.release <- struct{}{}
+	manager.Wait()
+
+	recorder := httptest.NewRecorder()
+	jobMetrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, expected := range []string{"firefly_urls_processed_total 1\n", "firefly_extract_duration_seconds_count 1\n"} {
+		if !strings.Contains(recorder.Body.String(), expected) {
+			t.Errorf("expected the URL of the job in the metrics, got no %q", expected)
+		}
+	}
+}
@@ -1,20 +1,41 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"firefly-assignment/article"
+	"firefly-assignment/checkpoint"
 	"firefly-assignment/config"
 	"firefly-assignment/display"
+	"firefly-assignment/metrics"
 	"firefly-assignment/network"
+	"firefly-assignment/partialResult"
+	"firefly-assignment/pipeline"
+	"firefly-assignment/progress"
+	"firefly-assignment/resultStore"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/semaphore"
+	"firefly-assignment/tracing"
+	"firefly-assignment/trends"
+	"firefly-assignment/urlSource"
 	"firefly-assignment/utils"
+	"firefly-assignment/warc"
 	"firefly-assignment/wordBank"
-	"firefly-assignment/wordOps"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/spf13/pflag"
 	"golang.org/x/time/rate"
 )
 
@@ -22,122 +43,811 @@ import (
 var (
 	nResults              int
 	sourceUrlFileName     string
+	inputSources          []string
+	pagesDir              string
+	outputPath            string
+	outputFormat          string
+	summaryOutput         string
+	partialOutput         string
+	databasePath          string
+	trendsOutput          string
+	warcOutput            string
+	checkpointPath        string
+	checkpointInterval    time.Duration
+	resume                bool
+	listenAddress         string
+	maxRunningJobs        int
+	jobHistory            int
+	metricsAddress        string
+	traceOutput           string
 	requestsPerSecond     rate.Limit
 	burstSize             int
 	maxConcurrentRequests int
+	showProgress          bool
 )
 
+// Job components, built from the configuration
 var (
-	wg               sync.WaitGroup
-	wordBankChannel  chan utils.WordBank    = make(chan utils.WordBank, 1)
-	validWords       utils.WordBank         = make(utils.WordBank)
-	wordFrequencyMap utils.WordFrequencyMap = make(utils.WordFrequencyMap)
-	processedURLs    int32                  = 0
-	erroredURLs      int32                  = 0
-
-	semaphoreMaxConcRequests chan struct{}
+	formatter display.Formatter
+	fetcher   *network.Fetcher
+	extractor *article.Extractor
+	bank      *wordBank.Bank
+	archive   *os.File
+	journal   *checkpoint.Journal
 )
 
-// processURL processes a URL by first fetching the raw content from the URL, scraping an article and finally updating the word frequency map.
-func processURL(url string) {
+var (
+	wg                sync.WaitGroup
+	wordBanksChannel  chan utils.LanguageWordBanks = make(chan utils.LanguageWordBanks, 1)
+	wordBanksLoadOnce sync.Once
+	wordBanksOnce     sync.Once
+	validWords        utils.LanguageWordBanks
+	articleCounter    *pipeline.Pipeline // Built once the word banks are loaded (see articlePipeline)
+	totals            *pipeline.Totals   = pipeline.NewTotals()
+	processedURLs     int32              = 0
+	erroredURLs       int32              = 0
+	quietURLLogs      bool
+	summary           *runSummary.Summary
+	storedRun         *resultStore.Run
+	trendTracker      *trends.Tracker
+	jobMetrics        *metrics.Metrics
+	tracer            *tracing.Tracer
+	traceFile         *os.File
+	journalOnce       sync.Once
+
+	// jobContext is canceled when the job is interrupted (see handleInterrupts).
+	jobContext context.Context = context.Background()
+
+	// The rate limiter and concurrency limit can be adjusted while the job is running (see watchConfig).
+	limiter                  *rate.Limiter
+	semaphoreMaxConcRequests *semaphore.Semaphore
+	configLoader             *config.Loader
+	configMutex              sync.Mutex
+)
+
+// errInterrupted is returned by the commands that were interrupted by SIGINT or SIGTERM.
+var errInterrupted = errors.New("interrupted")
+
+// command is a subcommand of the application.
+type command struct {
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+// commands maps the name of each subcommand to its implementation.
+// If no subcommand is given, the `run` command is used.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"run":      {usage: "run [flags]", description: "Fetch the URLs and count the words (default)", run: runCommand},
+		"crawl":    {usage: "crawl [flags]", description: "Fetch the URLs and store the pages in 'pages_dir'", run: crawlCommand},
+		"analyze":  {usage: "analyze [flags]", description: "Count the words of the pages stored in 'pages_dir'", run: analyzeCommand},
+		"wordbank": {usage: "wordbank compile SOURCE | wordbank inspect [WORD...]", description: "Compile a word bank from a URL or file, or inspect the configured word banks", run: wordBankCommand},
+		"inspect":  {usage: "inspect [flags] URL", description: "Show what the extractor pulls from one page and which words count", run: inspectCommand},
+		"replay":   {usage: "replay [flags] WARC_FILE...", description: "Count the words of the pages archived in WARC files, without fetching them", run: replayCommand},
+		"diff":     {usage: "diff [flags] A B", description: "Compare the words of two stored runs (by ID) or URL lists (by path)", run: diffCommand},
+		"runs":     {usage: "runs list | runs export RUN_ID", description: "List the runs stored in 'database', or export the top results of a stored run", run: runsCommand},
+		"serve":    {usage: "serve [flags]", description: "Serve the HTTP API to submit jobs, poll them and fetch their results", run: serveCommand},
+		"merge":    {usage: "merge [flags] PARTIAL_FILE...", description: "Merge the partial results written with 'partial_output' into one result", run: mergeCommand},
+	}
+}
+
+// pageHandler handles the body of a fetched URL, recording its stages in the trace of the URL, if any.
+type pageHandler func(url string, body string, trace *tracing.Trace) error
+
+// processURL processes a URL by first fetching the raw content from the URL, and then handing the body over to the given handler.
+// The stages of the URL are recorded in its trace, if any, which ends with the URL.
+func processURL(url string, trace *tracing.Trace, handle pageHandler) {
 	// Use a semaphore (with size `maxConcRequests`) to limit the number of concurrent URLs processed.
-	semaphoreMaxConcRequests <- struct{}{}
-	defer func() { <-semaphoreMaxConcRequests }()
+	waitStart := time.Now()
+	semaphoreMaxConcRequests.Acquire()
+	defer semaphoreMaxConcRequests.Release()
+	trace.Since("semaphore_wait", waitStart)
 
 	defer wg.Done()
 
-	fmt.Printf("\n[INFO] - Processing URL: %v", url)
+	// Once the job is interrupted, the URLs waiting for the semaphore are skipped, so that it drains quickly.
+	if err := interruption(); err != nil {
+		trace.End(err)
+		return
+	}
+
+	if !quietURLLogs {
+		slog.Debug("Processing URL", "url", url)
+	}
 
-	body, err := network.FetchContent(url)
+	body, err := fetchContent(url, trace)
 	if err != nil {
-		fmt.Printf("\n[ERROR] - Failed to fetch URL: %v with Error: %v", url, err)
-		erroredURLs++
+		recordError("Failed to fetch URL", url, err)
+		trace.End(err)
 		return
 	}
 
-	articleWords, err := article.GetArticleWords(body)
-	if err != nil {
-		fmt.Printf("\n[ERROR] - Failed to extract article content for URL: %v with Error: %v", url, err)
-		erroredURLs++
+	if err := handle(url, body, trace); err != nil {
+		recordError("Failed to process URL", url, err)
+		trace.End(err)
 		return
 	}
 
-	// Initialize the word bank of valid words.
-	if len(validWords) == 0 {
-		validWords = <-wordBankChannel
+	recordProcessed()
+	trace.End(nil)
+}
+
+// fetchContent fetches a URL, recording the stages of its requests in its trace, if any.
+func fetchContent(url string, trace *tracing.Trace) (string, error) {
+	if trace == nil {
+		return fetcher.FetchContent(url)
+	}
+	return fetcher.FetchContentTraced(url, trace)
+}
+
+// recordProcessed counts a processed URL in the totals and in the metrics.
+func recordProcessed() {
+	atomic.AddInt32(&processedURLs, 1)
+	if jobMetrics != nil {
+		jobMetrics.URLProcessed()
+	}
+}
+
+// recordError logs the error of a URL, and counts it in the totals, in the run summary and in the metrics.
+func recordError(message string, url string, err error) {
+	slog.Error(message, "url", url, "class", runSummary.ErrorClass(err), "error", err)
+	atomic.AddInt32(&erroredURLs, 1)
+	summary.AddError(url, err)
+	if storedRun != nil {
+		storedRun.AddError(url, err)
+	}
+	if jobMetrics != nil {
+		jobMetrics.URLErrored(err)
+	}
+}
+
+// processURLs fetches all URLs concurrently, respecting the configured rate limit and concurrency limit,
+// and hands each fetched body over to the given handler. With `trace_output` set, each URL is traced.
+// When the job is interrupted, the URLs that are not fetched yet are skipped, the URLs in flight are
+// completed, and errInterrupted is returned.
+func processURLs(urls []string, handle pageHandler) error {
+	if showProgress {
+		reporter := newProgressReporter(len(urls))
+		reporter.Start()
+		defer reporter.Stop()
+	}
+
+	for _, url := range urls {
+		var trace *tracing.Trace
+		if tracer != nil {
+			trace = tracer.Start("url", map[string]string{"url": url})
+		}
+		waitStart := time.Now()
+		if err := limiter.Wait(jobContext); err != nil && jobContext.Err() != nil {
+			trace.End(errInterrupted)
+			break
+		}
+		trace.Since("limiter_wait", waitStart)
+
+		wg.Add(1)
+		go processURL(url, trace, handle)
+	}
+	wg.Wait()
+
+	if err := interruption(); err != nil {
+		slog.Warn("Interrupted, skipped the URLs that were not fetched yet",
+			"total", len(urls), "processed", atomic.LoadInt32(&processedURLs), "errored", atomic.LoadInt32(&erroredURLs))
+		return err
+	}
+	return nil
+}
+
+// newProgressReporter creates a progress reporter that writes the counters of the job to stderr. The progress is
+// only drawn live when stdout is a terminal, so that results redirected to a file (`firefly > out.json`) come
+// with periodic log lines instead, and only if stderr is a terminal too, as the live view is drawn there.
+// In the live view, the per-URL log lines are silenced, so that they do not break it.
+func newProgressReporter(total int) *progress.Reporter {
+	interactive := progress.IsTerminal(os.Stdout) && progress.IsTerminal(os.Stderr)
+	reporter := progress.New(os.Stderr, func() progress.Stats {
+		return progress.Stats{
+			Total:     total,
+			Processed: int(atomic.LoadInt32(&processedURLs)),
+			Errored:   int(atomic.LoadInt32(&erroredURLs)),
+			InFlight:  semaphoreMaxConcRequests.InUse(),
+			RateLimit: float64(limiter.Limit()),
+		}
+	}, progress.WithInteractive(interactive))
+	quietURLLogs = reporter.Interactive()
+	return reporter
+}
+
+// countArticle scrapes an article from the page body, adds its counts to the results,
+// and records them in the checkpoint journal, if any. The parsing and the counting are recorded in the trace, if any.
+func countArticle(url string, body string, trace *tracing.Trace) error {
+	counter := articlePipeline()
+	start := time.Now()
+	articleContent, err := counter.Extract(body)
+	trace.Since("parse", start)
+	if jobMetrics != nil {
+		jobMetrics.ExtractFinished(time.Since(start))
+	}
+	if err != nil {
+		return err
+	}
+
+	countStart := time.Now()
+	result := counter.Count(url, articleContent)
+	addArticle(result)
+	trace.Since("count", countStart)
+
+	if journal != nil {
+		if err := journal.Record(checkpoint.Entry(result)); err != nil {
+			slog.Warn("Could not record the URL in the checkpoint", "url", url, "error", err)
+		}
+	}
+	return nil
+}
+
+// addArticle merges the counts of an article into the totals of the job, and adds the results of the article
+// to the run summary, the stored run and the trends, if any.
+func addArticle(result pipeline.Result) {
+	summary.AddArticle(totals.Add(result))
+	if storedRun != nil {
+		storedRun.AddArticle(result.URL, result.Counts, result.Entities)
+	}
+	if trendTracker != nil {
+		trendTracker.Add(result.Published, result.Counts)
+	}
+}
+
+// openCheckpoint opens the checkpoint journal, if `checkpoint` is set. When resuming, the URLs
+// completed by the previous run are split off, so that they are not fetched again.
+// The journal is saved when the job ends, even if it is interrupted (see closeCheckpoint).
+//
+// Parameters:
+//   - urls: The URLs of the job.
+//
+// Returns:
+//   - []string: The URLs that remain to be fetched.
+//   - []checkpoint.Entry: The entries of the completed URLs, whose counts must be merged into the results.
+//   - error: An error if the checkpoint file cannot be read or opened.
+func openCheckpoint(urls []string) ([]string, []checkpoint.Entry, error) {
+	if checkpointPath == "" {
+		return urls, nil, nil
+	}
+
+	var entries []checkpoint.Entry
+	var err error
+	journal, entries, err = checkpoint.Open(checkpointPath, resume, checkpoint.WithFlushInterval(checkpointInterval))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Skip each URL as many times as it was completed, so that duplicate URLs are counted as often as in a single run.
+	pending := checkpoint.NewPending(entries)
+	var remaining []string
+	var completed []checkpoint.Entry
+	for _, url := range urls {
+		if entry, done := pending.Take(url); done {
+			completed = append(completed, entry)
+		} else {
+			remaining = append(remaining, url)
+		}
+	}
+	if resume {
+		slog.Info("Resuming from checkpoint", "path", checkpointPath, "completed", len(completed), "remaining", len(remaining))
+	}
+	if len(pending) > 0 {
+		slog.Warn("Ignoring checkpoint entries of URLs that are not in the input", "urls", len(pending))
+	}
+
+	return remaining, completed, nil
+}
+
+// closeCheckpoint flushes and closes the checkpoint journal, if any.
+func closeCheckpoint() {
+	if journal == nil {
+		return
 	}
+	journalOnce.Do(func() {
+		if err := journal.Close(); err != nil {
+			slog.Error("Could not save the checkpoint", "path", checkpointPath, "error", err)
+		}
+	})
+}
 
-	wordOps.CountWords(articleWords, validWords, wordFrequencyMap)
-	processedURLs++
+// articlePipeline returns the pipeline that counts the articles of the job, waiting the first time for
+// the word banks loaded by initializeWordBanks.
+func articlePipeline() *pipeline.Pipeline {
+	wordBanksOnce.Do(func() {
+		validWords = <-wordBanksChannel
+		articleCounter = pipeline.New(extractor, validWords)
+	})
+	return articleCounter
 }
 
-// getURLsFromFile gets the URLs for the articles to be scraped from the configured file
+// getURLsFromFile gets the URLs for the articles to be scraped from the configured input sources,
+// or (by default) from the configured file in the 'static' folder.
 func getURLsFromFile() ([]string, error) {
-	file, err := os.Open("static/" + sourceUrlFileName)
+	sources := inputSources
+	if len(sources) == 0 {
+		sources = []string{"static/" + sourceUrlFileName}
+	}
+	return readURLs(sources...)
+}
+
+// readURLs reads the URLs of the given sources (see urlSource), fetching the remote ones within the rate limit.
+func readURLs(sources ...string) ([]string, error) {
+	reader := urlSource.New(urlSource.WithFetch(func(url string) (string, error) {
+		if err := limiter.Wait(jobContext); err != nil {
+			return "", err
+		}
+		return fetcher.FetchContent(url)
+	}))
+	return reader.Read(sources)
+}
+
+// writeOutput writes the results to the configured output file, or to stdout if no output file is configured.
+func writeOutput(write func(w io.Writer) error) error {
+	if outputPath == "" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(outputPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer file.Close()
 
-	// Create a scanner to read the file line by line
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	if err := write(file); err != nil {
+		return err
 	}
+	return file.Close()
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// finishSummary completes the run summary with the totals of the run.
+func finishSummary(total int) {
+	summary.Totals = runSummary.Totals{
+		Total:     total,
+		Processed: int(atomic.LoadInt32(&processedURLs)),
+		Errored:   int(atomic.LoadInt32(&erroredURLs)),
+	}
+	summary.Finish()
+}
+
+// printSummary logs the totals and the error counts by class of the run summary to stderr.
+func printSummary(processedLabel string) {
+	fmt.Fprintf(os.Stderr, "\n========")
+	fmt.Fprintf(os.Stderr, "\nTotal entries: %v", summary.Totals.Total)
+	fmt.Fprintf(os.Stderr, "\n%v entries: %v", processedLabel, summary.Totals.Processed)
+	fmt.Fprintf(os.Stderr, "\nErrored entries: %v", summary.Totals.Errored)
+
+	classes := make([]string, 0, len(summary.Errors))
+	for class := range summary.Errors {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		errorCount := summary.Errors[class]
+		fmt.Fprintf(os.Stderr, "\n  %v: %v (e.g. %v)", class, errorCount.Count, strings.Join(errorCount.ExampleURLs, ", "))
 	}
 
-	return lines, nil
+	fmt.Fprintf(os.Stderr, "\nDuration: %.1fs\n", summary.DurationSeconds)
 }
 
-func main() {
+// saveSummary writes the run summary to the file set with `summary_output`, if any.
+func saveSummary() {
+	if summaryOutput == "" {
+		return
+	}
+	if err := summary.Save(summaryOutput); err != nil {
+		slog.Error("Could not write the summary", "path", summaryOutput, "error", err)
+	}
+}
+
+// savePartial writes the mergeable partial result of the run to the file set with `partial_output`, if any.
+func savePartial() {
+	if partialOutput == "" {
+		return
+	}
+
+	partial := &partialResult.Result{
+		Version:             partialResult.FormatVersion,
+		Documents:           len(summary.Articles),
+		Totals:              summary.Totals,
+		Errors:              summary.Errors,
+		Languages:           totals.Languages,
+		Counts:              totals.Words,
+		Entities:            totals.Entities,
+		DocumentFrequencies: totals.DocumentFrequencies,
+		Articles:            summary.Articles,
+	}
+	if err := partial.Save(partialOutput); err != nil {
+		slog.Error("Could not write the partial result", "path", partialOutput, "error", err)
+	}
+}
+
+// saveRun stores the results of the run in the database set with `database`, if any.
+func saveRun() {
+	if storedRun == nil {
+		return
+	}
+
+	store, err := resultStore.Open(databasePath)
+	if err != nil {
+		slog.Error("Could not store the run", "error", err)
+		return
+	}
+	defer store.Close()
+
+	storedRun.Words = totals.Words
+	storedRun.Entities = totals.Entities
+	id, err := store.Save(storedRun)
+	if err != nil {
+		slog.Error("Could not store the run", "path", databasePath, "error", err)
+		return
+	}
+	slog.Info("Stored the run", "path", databasePath, "id", id)
+}
+
+// saveTrends writes the frequency series of the top N words by publication date to the file set with
+// `trends_output`, if any: as JSON if the file name ends with ".json", and as CSV otherwise.
+func saveTrends() {
+	if trendTracker == nil {
+		return
+	}
+
+	series := trendTracker.Series(nResults)
+	if series.Undated > 0 {
+		slog.Warn("Some articles have no publication date and are left out of the trends", "articles", series.Undated)
+	}
+	if series.Outliers > 0 {
+		slog.Warn("Some articles have outlier publication dates and are left out of the trends", "articles", series.Outliers, "max_buckets", trends.MaxBuckets)
+	}
+
+	file, err := os.Create(trendsOutput)
+	if err == nil {
+		if strings.HasSuffix(trendsOutput, ".json") {
+			err = series.WriteJSON(file)
+		} else {
+			err = series.WriteCSV(file)
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		slog.Error("Could not write the trends", "path", trendsOutput, "error", err)
+	}
+}
+
+// printResults completes the run summary with the top N words, entities and TF-IDF scores, logs the summary,
+// writes the top N words to the output in the configured format, and saves the summary, the partial result,
+// the trends and the run.
+func printResults(total int) {
+	totals.Top(nResults, summary)
+	finishSummary(total)
+
+	// Print the summary to stderr, so that it does not mix with the output
+	printSummary("Processed")
+	fmt.Fprintf(os.Stderr, "Languages: %v", summary.Languages)
+	fmt.Fprintf(os.Stderr, "\nTop %v entities:\n", nResults)
+	if err := (display.TableFormatter{}).Format(os.Stderr, summary.TopEntities); err != nil {
+		slog.Error("Could not print the entities", "error", err)
+	}
+	fmt.Fprintf(os.Stderr, "Top %v words:\n", nResults)
+
+	// Print output. Report formats render the whole summary rather than only the top words.
+	write := func(w io.Writer) error { return formatter.Format(w, summary.TopWords) }
+	if reportFormatter, ok := formatter.(display.ReportFormatter); ok {
+		write = func(w io.Writer) error { return reportFormatter.FormatReport(w, summary) }
+	}
+	if err := writeOutput(write); err != nil {
+		slog.Error("Could not write the output", "path", outputPath, "error", err)
+	}
+
+	saveSummary()
+	savePartial()
+	saveTrends()
+	saveRun()
+}
+
+// loadConfig parses the command-line flags of the command, loads the configuration and
+// sets the configuration settings.
+//
+// Returns:
+//   - []string: The positional arguments of the command.
+//   - error: An error if the flags cannot be parsed or the configuration is invalid.
+func loadConfig(name string, args []string) ([]string, error) {
+	// Parse the command-line flags, which override the configuration file.
+	loader := config.NewLoader(name)
+	positionalArgs, err := loader.ParseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load config from 'config.yaml' if available.
-	config.LoadConfig()
+	appConfig, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Log with the configured format and level from now on.
+	slog.SetDefault(appConfig.NewLogger(os.Stderr))
+	if configFile := loader.ConfigFileUsed(); configFile != "" {
+		slog.Debug("Loaded configuration file", "path", configFile)
+	} else {
+		slog.Info("No configuration file found, using default values")
+	}
 
 	// Set Configuration settings
-	nResults = config.AppConfig.TopResults
-	sourceUrlFileName = config.AppConfig.SourceURLFileName
-	requestsPerSecond = config.AppConfig.RequestsPerSecond
-	burstSize = config.AppConfig.BurstSize
-	maxConcurrentRequests = config.AppConfig.MaxConcurrentRequests
-	semaphoreMaxConcRequests = make(chan struct{}, maxConcurrentRequests)
+	nResults = appConfig.TopResults
+	sourceUrlFileName = appConfig.SourceURLFileName
+	inputSources = appConfig.Input
+	pagesDir = appConfig.PagesDir
+	outputPath = appConfig.Output
+	outputFormat = appConfig.OutputFormat
+	summaryOutput = appConfig.SummaryOutput
+	partialOutput = appConfig.PartialOutput
+	databasePath = appConfig.Database
+	trendsOutput = appConfig.TrendsOutput
+	warcOutput = appConfig.WARCOutput
+	checkpointPath = appConfig.Checkpoint
+	checkpointInterval = appConfig.CheckpointInterval
+	resume = appConfig.Resume
+	listenAddress = appConfig.ListenAddress
+	maxRunningJobs = appConfig.MaxRunningJobs
+	jobHistory = appConfig.JobHistory
+	metricsAddress = appConfig.MetricsAddress
+	traceOutput = appConfig.TraceOutput
+	requestsPerSecond = appConfig.RequestsPerSecond
+	burstSize = appConfig.BurstSize
+	maxConcurrentRequests = appConfig.MaxConcurrentRequests
+	showProgress = appConfig.Progress && !appConfig.Quiet
+	limiter = rate.NewLimiter(requestsPerSecond, burstSize)
+	semaphoreMaxConcRequests = semaphore.New(maxConcurrentRequests)
+	configLoader = loader
+	summary = runSummary.New(name, loader.Settings())
+	if databasePath != "" {
+		storedRun = resultStore.NewRun(summary)
+	}
+	if trendsOutput != "" {
+		if trendTracker, err = trends.NewTracker(appConfig.TrendsGranularity); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build the job components
+	formatter, err = display.NewFormatter(outputFormat)
+	if err != nil {
+		return nil, err
+	}
+	var fetcherOpts []network.FetcherOption
+	if warcOutput != "" {
+		var archiver *warc.Writer
+		archiver, archive, err = warc.Create(warcOutput, map[string]string{"software": programName(), "format": "WARC File Format 1.1"})
+		if err != nil {
+			return nil, err
+		}
+		fetcherOpts = append(fetcherOpts, network.WithArchiver(archiver))
+		// Pages served from the cache are not requested, so they would be missing from the archive.
+		if !appConfig.NoCache && !appConfig.Refresh {
+			slog.Warn("Refreshing the cached pages, so that the WARC file archives all of them", "warc_output", warcOutput)
+			appConfig.Refresh = true
+		}
+	}
+	if traceOutput != "" {
+		if tracer, traceFile, err = tracing.Create(traceOutput); err != nil {
+			return nil, err
+		}
+		fetcherOpts = append(fetcherOpts, network.WithDialTracing(true))
+	}
+	if metricsAddress != "" {
+		jobMetrics = metrics.New(metrics.WithLimiter(limiter))
+		fetcherOpts = append(fetcherOpts, network.WithObserver(jobMetrics))
+		serveMetrics()
+	}
+	fetcher, err = appConfig.NewFetcher(fetcherOpts...)
+	if err != nil {
+		return nil, err
+	}
+	extractor = appConfig.NewExtractor()
+	bank = appConfig.NewBank()
+
+	return positionalArgs, nil
+}
+
+// watchConfig watches the configuration file for changes during a long-running job, and applies
+// changes to the rate limit and the concurrency limit live.
+func watchConfig() {
+	watching := configLoader.Watch(applyConfigChange, func(err error) {
+		slog.Warn("Ignoring configuration change", "error", err)
+	})
+	if watching {
+		slog.Info("Watching the configuration file for changes", "path", configLoader.ConfigFileUsed())
+	}
+}
+
+// applyConfigChange applies the changed `requests_per_second`, `burst_size` and `max_concurrent_requests`
+// settings to the running job, and logs each applied change. Other settings only take effect on the next run.
+func applyConfigChange(changed config.Config) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
 
-	// 1. Initialize the word bank of valid words
-	go wordBank.Initialize(wordBankChannel)
+	if changed.RequestsPerSecond != requestsPerSecond {
+		slog.Info("Applied config change", "key", "requests_per_second", "old", requestsPerSecond, "new", changed.RequestsPerSecond)
+		requestsPerSecond = changed.RequestsPerSecond
+		limiter.SetLimit(requestsPerSecond)
+	}
+
+	if changed.BurstSize != burstSize {
+		slog.Info("Applied config change", "key", "burst_size", "old", burstSize, "new", changed.BurstSize)
+		burstSize = changed.BurstSize
+		limiter.SetBurst(burstSize)
+	}
+
+	if changed.MaxConcurrentRequests != maxConcurrentRequests {
+		slog.Info("Applied config change", "key", "max_concurrent_requests", "old", maxConcurrentRequests, "new", changed.MaxConcurrentRequests)
+		maxConcurrentRequests = changed.MaxConcurrentRequests
+		semaphoreMaxConcRequests.SetLimit(maxConcurrentRequests)
+	}
+}
+
+// runCommand fetches the configured URLs, counts the words of each article and prints the results.
+func runCommand(args []string) error {
+	if _, err := loadConfig(programName()+" run", args); err != nil {
+		return err
+	}
+
+	// 1. Initialize the word banks of valid words
+	initializeWordBanks()
 
 	// 2. Get the URLs from file
 	urls, err := getURLsFromFile()
 
 	if err != nil {
-		log.Fatalln("[ERROR] - No URLs to fetch content from")
+		return fmt.Errorf("no URLs to fetch content from: %w", err)
+	}
+
+	// 3. Skip the URLs completed by a previous run, when resuming from a checkpoint.
+	remaining, completed, err := openCheckpoint(urls)
+	if err != nil {
+		return err
 	}
 
-	limiter := rate.NewLimiter(requestsPerSecond, burstSize)
+	// 4. For each URL, scrape and process the data.
+	watchConfig()
+	interruptErr := processURLs(remaining, countArticle)
+	closeCheckpoint()
+	if interruptErr != nil && journal != nil {
+		slog.Warn("Run again with --resume to continue", "checkpoint", checkpointPath)
+	}
 
-	// 3. For each URL, scrape and process the data.
-	for _, url := range urls {
-		limiter.Wait(context.Background())
-		wg.Add(1)
-		go processURL(url)
+	// 5. Merge the counts of the completed URLs, as if they had been fetched again.
+	for _, entry := range completed {
+		addArticle(pipeline.Result(entry))
+		atomic.AddInt32(&processedURLs, 1)
 	}
-	wg.Wait()
 
-	// 4. Get Top N words
-	var topNWords = wordOps.GetTopNWords(nResults, wordFrequencyMap)
+	// 6. Get Top N words and print them, also those of the completed URLs of an interrupted job.
+	printResults(len(urls))
+	return interruptErr
+}
 
-	output, err := display.GetPrettyJSON(topNWords)
-	if err != nil {
-		fmt.Println("[ERROR] - Could not print output.")
+// serveMetrics serves the metrics of the job at /metrics on `metrics_address` in the background, for as long as the command runs.
+func serveMetrics() {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", jobMetrics.Handler())
+	metricsServer := &http.Server{Addr: metricsAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		slog.Info("Serving the metrics", "address", metricsAddress)
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Could not serve the metrics", "address", metricsAddress, "error", err)
+		}
+	}()
+}
+
+// closeTraces writes the remaining spans of the job to the trace file, if any, and prints the summary of the slowest stages.
+func closeTraces() {
+	if tracer == nil {
+		return
+	}
+	if err := tracer.Flush(); err != nil {
+		slog.Error("Could not write the trace file", "path", traceOutput, "error", err)
+	}
+	if err := traceFile.Close(); err != nil {
+		slog.Error("Could not close the trace file", "path", traceOutput, "error", err)
 	}
 
-	// Print output
-	fmt.Printf("\n\n========")
-	fmt.Printf("\nTotal entries: %v", len(urls))
-	fmt.Printf("\nProcessed entries: %v", processedURLs)
-	fmt.Printf("\nErrored entries: %v", erroredURLs)
-	fmt.Println("\nTop 10 words:")
-	fmt.Println(output)
+	if summary := tracer.Summary(); len(summary) > 0 {
+		fmt.Fprintln(os.Stderr, "Slowest stages:")
+		if err := tracer.WriteSummary(os.Stderr); err != nil {
+			slog.Error("Could not print the stages", "error", err)
+		}
+	}
+}
+
+// closeArchive closes the WARC file of the job, if any.
+func closeArchive() {
+	if archive == nil {
+		return
+	}
+	if err := archive.Close(); err != nil {
+		slog.Error("Could not close the WARC file", "path", warcOutput, "error", err)
+	}
+}
+
+// initializeWordBanks loads the word banks in the background, once, and exits if they cannot be loaded,
+// since no article can be counted without them.
+func initializeWordBanks() {
+	wordBanksLoadOnce.Do(func() {
+		go func() {
+			if err := bank.InitializeLanguages(wordBanksChannel); err != nil {
+				slog.Error("Could not load the word banks", "error", err)
+				os.Exit(1)
+			}
+		}()
+	})
+}
+
+// handleInterrupts returns a context that is canceled on the first SIGINT or SIGTERM, so that the command
+// stops, writes its results and closes its files as usual. A second signal stops the command at once.
+func handleInterrupts() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		slog.Warn("Interrupted, stopping after the work in progress (interrupt again to stop at once)")
+		stop()
+	}()
+	return ctx
+}
+
+// interruption returns errInterrupted once the job is interrupted, and nil until then.
+func interruption() error {
+	if jobContext.Err() != nil {
+		return errInterrupted
+	}
+	return nil
+}
+
+// programName returns the name of the executable.
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// printUsage prints the list of subcommands.
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags]\n\nCommands:\n", programName())
+	for _, name := range []string{"run", "crawl", "analyze", "replay", "runs", "diff", "merge", "serve", "wordbank", "inspect"} {
+		fmt.Fprintf(os.Stderr, "  %-55s %s\n", commands[name].usage, commands[name].description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s [command] --help' to list the flags of a command.\n", programName())
+}
+
+func main() {
+	// Select the subcommand; without one, run the full job for backwards compatibility.
+	name, args := "run", os.Args[1:]
+	if len(args) > 0 {
+		if _, exists := commands[args[0]]; exists {
+			name, args = args[0], args[1:]
+		} else if args[0] == "help" {
+			printUsage()
+			return
+		}
+	}
+
+	jobContext = handleInterrupts()
+	err := commands[name].run(args)
+	closeCheckpoint()
+	closeArchive()
+	closeTraces()
+	if err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			os.Exit(0)
+		}
+		if errors.Is(err, errInterrupted) {
+			os.Exit(130)
+		}
+		slog.Error("Command failed", "command", name, "error", err)
+		os.Exit(1)
+	}
 }
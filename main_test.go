@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestRunInterrupted(t *testing.T) {
+	// The second page interrupts the job while it is fetched, before the third one is due.
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /words.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "phone\ngreat")
+	})
+	mux.HandleFunc("GET /{page}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("page") == "2" {
+			syscall.Kill(os.Getpid(), syscall.SIGINT)
+			<-jobContext.Done()
+		}
+		fmt.Fprintln(w, `<html><body><div class="caas-body">The phone is great.</div></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	urlsPath := filepath.Join(dir, "urls.txt")
+	archivePath := filepath.Join(dir, "run.warc.gz")
+	if err := os.WriteFile(urlsPath, []byte(fmt.Sprintf("%[1]v/1\n%[1]v/2\n%[1]v/3\n", server.URL)), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jobContext = handleInterrupts()
+	err := runCommand([]string{
+		"--input", urlsPath, "--word-bank-url", server.URL + "/words.txt", "--warc-output", archivePath,
+		"--output", filepath.Join(dir, "out.json"), "--no-cache", "--progress=false",
+		"--requests-per-second", "1", "--burst-size", "1",
+	})
+	closeArchive()
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("expected errInterrupted, got %v", err)
+	}
+
+	// The pages fetched before the interruption are archived, and the archive reads back to its end.
+	var archived []string
+	if err := readArchivedPages(archivePath, func(url string, body string) {
+		archived = append(archived, url)
+	}); err != nil {
+		t.Fatalf("unexpected error reading the archive: %v", err)
+	}
+	expected := []string{server.URL + "/1", server.URL + "/2"}
+	if !reflect.DeepEqual(archived, expected) {
+		t.Errorf("expected the archived pages %v, got %v", expected, archived)
+	}
+	if summary.Totals.Total != 3 || summary.Totals.Processed != 2 {
+		t.Errorf("expected 2 of 3 URLs processed, got %+v", summary.Totals)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Package metrics collects the metrics of a long-running job, and exposes them in the Prometheus text format,
+so that a crawl can be observed while it runs. The Metrics observe the requests of a network.Fetcher (see
+network.WithObserver), and are told by the job of each processed or failed URL, and of each extraction.
+
+Metrics:
+  - firefly_urls_processed_total: URLs whose article was counted.
+  - firefly_urls_errored_total{class}: URLs that failed, by error class (see runSummary.ErrorClass).
+  - firefly_retries_total: Failed requests that were retried.
+  - firefly_redirects_total: Redirects that were followed.
+  - firefly_downloaded_bytes_total: Bytes of the response bodies.
+  - firefly_fetch_duration_seconds: Time taken to fetch a URL, including its retries and redirects.
+  - firefly_extract_duration_seconds: Time taken to extract the article of a page.
+  - firefly_requests_in_flight: Requests in progress.
+  - firefly_limiter_tokens: Tokens available in the rate limiter, if any; negative while requests wait for tokens.
+
+The metrics of the Go runtime and of the process are exposed as well.
+*/
+package metrics
+
+import (
+	"firefly-assignment/runSummary"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/time/rate"
+)
+
+// namespace prefixes the names of the metrics.
+const namespace = "firefly"
+
+// Metrics holds the collectors of a job in their own registry. It is safe for concurrent use.
+type Metrics struct {
+	registry        *prometheus.Registry
+	processedURLs   prometheus.Counter
+	erroredURLs     *prometheus.CounterVec
+	retries         prometheus.Counter
+	redirects       prometheus.Counter
+	downloadedBytes prometheus.Counter
+	fetchDuration   prometheus.Histogram
+	extractDuration prometheus.Histogram
+	inFlight        prometheus.Gauge
+}
+
+// Option configures Metrics.
+type Option func(m *Metrics)
+
+// WithLimiter exposes the tokens available in the rate limiter, to show whether the job is held back by the rate limit.
+func WithLimiter(limiter *rate.Limiter) Option {
+	return func(m *Metrics) {
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "limiter_tokens",
+			Help:      "Tokens available in the rate limiter, negative while requests wait for tokens.",
+		}, func() float64 { return limiter.Tokens() }))
+	}
+}
+
+// New creates Metrics with all counters at zero, overridden by the given options.
+//
+// Parameters:
+//   - opts: The options to apply to the Metrics.
+//
+// Returns:
+//   - *Metrics: The Metrics.
+func New(opts ...Option) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		processedURLs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "urls_processed_total",
+			Help:      "URLs whose article was counted.",
+		}),
+		erroredURLs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "urls_errored_total",
+			Help:      "URLs that failed, by error class.",
+		}, []string{"class"}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Failed requests that were retried.",
+		}),
+		redirects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "redirects_total",
+			Help:      "Redirects that were followed.",
+		}),
+		downloadedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downloaded_bytes_total",
+			Help:      "Bytes of the response bodies.",
+		}),
+		fetchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fetch_duration_seconds",
+			Help:      "Time taken to fetch a URL, including its retries and redirects.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		extractDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "extract_duration_seconds",
+			Help:      "Time taken to extract the article of a page.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 8),
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "requests_in_flight",
+			Help:      "Requests in progress.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.processedURLs, m.erroredURLs, m.retries, m.redirects, m.downloadedBytes,
+		m.fetchDuration, m.extractDuration, m.inFlight,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Handler returns the HTTP handler that exposes the metrics in the Prometheus text format.
+//
+// Returns:
+//   - http.Handler: The handler of the /metrics endpoint.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// URLProcessed counts a URL whose article was counted.
+func (m *Metrics) URLProcessed() {
+	m.processedURLs.Inc()
+}
+
+// URLErrored counts a URL that failed, under the class of its error.
+//
+// Parameters:
+//   - err: The error of the URL.
+func (m *Metrics) URLErrored(err error) {
+	m.erroredURLs.WithLabelValues(runSummary.ErrorClass(err)).Inc()
+}
+
+// ExtractFinished records the time taken to extract the article of a page.
+//
+// Parameters:
+//   - duration: The time taken by the extraction.
+func (m *Metrics) ExtractFinished(duration time.Duration) {
+	m.extractDuration.Observe(duration.Seconds())
+}
+
+// RequestStarted counts a request in progress. It implements network.Observer.
+func (m *Metrics) RequestStarted() {
+	m.inFlight.Inc()
+}
+
+// RequestFinished counts the bytes of a finished request. It implements network.Observer.
+func (m *Metrics) RequestFinished(bytes int) {
+	m.inFlight.Dec()
+	m.downloadedBytes.Add(float64(bytes))
+}
+
+// Retried counts a retried request. It implements network.Observer.
+func (m *Metrics) Retried() {
+	m.retries.Inc()
+}
+
+// Redirected counts a followed redirect. It implements network.Observer.
+func (m *Metrics) Redirected() {
+	m.redirects.Inc()
+}
+
+// FetchFinished records the time taken to fetch a URL. It implements network.Observer.
+func (m *Metrics) FetchFinished(duration time.Duration) {
+	m.fetchDuration.Observe(duration.Seconds())
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"firefly-assignment/article"
+	"firefly-assignment/network"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var _ network.Observer = (*Metrics)(nil)
+
+func TestHandler(t *testing.T) {
+	m := New(WithLimiter(rate.NewLimiter(1, 5)))
+	m.RequestStarted()
+	m.RequestStarted()
+	m.RequestFinished(1024)
+	m.Retried()
+	m.Redirected()
+	m.FetchFinished(300 * time.Millisecond)
+	m.ExtractFinished(time.Millisecond)
+	m.URLProcessed()
+	m.URLErrored(&network.StatusError{StatusCode: 404})
+	m.URLErrored(article.ErrNoContent)
+	m.URLErrored(article.ErrNoContent)
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+
+	tests := []struct {
+		name         string
+		expectedLine string
+	}{
+		{name: "Processed URLs", expectedLine: "firefly_urls_processed_total 1"},
+		{name: "Errored URLs by class", expectedLine: `firefly_urls_errored_total{class="no_content"} 2`},
+		{name: "Errored URLs of another class", expectedLine: `firefly_urls_errored_total{class="not_found"} 1`},
+		{name: "Retries", expectedLine: "firefly_retries_total 1"},
+		{name: "Redirects", expectedLine: "firefly_redirects_total 1"},
+		{name: "Downloaded bytes", expectedLine: "firefly_downloaded_bytes_total 1024"},
+		{name: "Requests in flight", expectedLine: "firefly_requests_in_flight 1"},
+		{name: "Fetch latency", expectedLine: `firefly_fetch_duration_seconds_bucket{le="0.5"} 1`},
+		{name: "Fetch latency below the observation", expectedLine: `firefly_fetch_duration_seconds_bucket{le="0.25"} 0`},
+		{name: "Extract latency", expectedLine: "firefly_extract_duration_seconds_count 1"},
+		{name: "Limiter tokens", expectedLine: "firefly_limiter_tokens 5"},
+		{name: "Runtime metrics", expectedLine: "# TYPE go_goroutines gauge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(string(body), tt.expectedLine+"\n") {
+				t.Errorf("expected the line %q in:\n%v", tt.expectedLine, string(body))
+			}
+		})
+	}
+}
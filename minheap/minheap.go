@@ -11,8 +11,14 @@ type MinHeap []utils.WordFreq
 // Len returns the length of the heap.
 func (h MinHeap) Len() int { return len(h) }
 
-// Less compares the frequency of two elements, used to order the heap.
-func (h MinHeap) Less(i, j int) bool { return h[i].Frequency < h[j].Frequency }
+// Less compares the frequency of two elements, used to order the heap. Words with the same frequency
+// are ordered in reverse alphabetical order, so that ties are always broken the same way.
+func (h MinHeap) Less(i, j int) bool {
+	if h[i].Frequency != h[j].Frequency {
+		return h[i].Frequency < h[j].Frequency
+	}
+	return h[i].Word > h[j].Word
+}
 
 // Swap swaps two elements in the heap.
 func (h MinHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
@@ -0,0 +1,308 @@
+package network
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default settings of a Cache.
+const (
+	DefaultCacheTTL     = 24 * time.Hour
+	DefaultCacheMaxSize = 500 << 20 // 500 MiB
+)
+
+// cacheFileExtension is the extension of the files of the cache entries.
+const cacheFileExtension = ".json"
+
+// CacheEntry is a cached response, with the validators used to revalidate it with a conditional request.
+type CacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	FreshUntil   time.Time `json:"fresh_until"`
+	Body         string    `json:"body"`
+}
+
+// Fresh reports whether the entry can be used without revalidating it with the server.
+func (e CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.FreshUntil)
+}
+
+// Cache is an on-disk cache of fetched pages. Each entry is stored in its own file, named after
+// the SHA-256 hash of its URL. Entries older than the TTL are dropped, and the least recently used
+// entries are evicted when the total size of the cache exceeds its maximum size.
+//
+// The size and the recency order of the files are kept in memory, loaded from the directory when the
+// cache is opened, so that only the index is locked and the files are read and written without the lock.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mutex sync.Mutex
+	files map[string]*list.Element // File names of the entries, to their element in order.
+	order *list.List               // cacheFile values, the most recently used first.
+	size  int64                    // Total size of the files.
+}
+
+// cacheFile is a file of the cache index.
+type cacheFile struct {
+	name string
+	size int64
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(c *Cache)
+
+// WithTTL sets how long a page is kept in the cache. Pages without caching headers are fresh for the whole TTL.
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) { c.ttl = ttl }
+}
+
+// WithMaxSize sets the maximum total size of the cache files, in bytes.
+func WithMaxSize(maxSize int64) CacheOption {
+	return func(c *Cache) { c.maxSize = maxSize }
+}
+
+// OpenCache opens the cache in the given directory, creating the directory if it does not exist, and
+// indexes its files, the most recently modified first.
+//
+// Parameters:
+//   - dir: The directory of the cache.
+//   - opts: The options to apply to the Cache.
+//
+// Returns:
+//   - *Cache: The opened cache.
+//   - error: An error if the directory cannot be created or read.
+func OpenCache(dir string, opts ...CacheOption) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	c := &Cache{dir: dir, ttl: DefaultCacheTTL, maxSize: DefaultCacheMaxSize, files: make(map[string]*list.Element), order: list.New()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes the files of the cache directory, the most recently modified first.
+func (c *Cache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("could not read cache directory: %w", err)
+	}
+
+	var infos []os.FileInfo
+	for _, dirEntry := range dirEntries {
+		if !strings.HasSuffix(dirEntry.Name(), cacheFileExtension) {
+			continue
+		}
+		if info, err := dirEntry.Info(); err == nil {
+			infos = append(infos, info)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().After(infos[j].ModTime()) })
+	for _, info := range infos {
+		c.files[info.Name()] = c.order.PushBack(cacheFile{name: info.Name(), size: info.Size()})
+		c.size += info.Size()
+	}
+	return nil
+}
+
+// Get returns the cached entry of the URL. Entries older than the TTL are removed and not returned.
+// It is safe to call Get concurrently.
+//
+// Parameters:
+//   - url: The URL of the page.
+//
+// Returns:
+//   - CacheEntry: The cached entry.
+//   - bool: True if the URL is in the cache.
+func (c *Cache) Get(url string) (CacheEntry, bool) {
+	path := c.path(url)
+	name := filepath.Base(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// The file may have been removed behind the cache's back.
+		c.forget(name)
+		return CacheEntry{}, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return CacheEntry{}, false
+	}
+
+	now := time.Now()
+	if now.Sub(entry.StoredAt) > c.ttl {
+		c.forget(name)
+		os.Remove(path)
+		return CacheEntry{}, false
+	}
+
+	// Mark the entry as recently used, so that it is evicted last, also by the next runs.
+	c.mutex.Lock()
+	if element, found := c.files[name]; found {
+		c.order.MoveToFront(element)
+	}
+	c.mutex.Unlock()
+	os.Chtimes(path, now, now)
+	return entry, true
+}
+
+// Put stores the entry, replacing any previous entry of its URL, and evicts the least recently used
+// entries if the cache exceeds its maximum size. It is safe to call Put concurrently.
+//
+// Parameters:
+//   - entry: The entry to store.
+//
+// Returns:
+//   - error: An error if the entry cannot be written.
+func (c *Cache) Put(entry CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode cache entry: %w", err)
+	}
+
+	// Write to a temporary file first, so that a concurrent reader never sees a partial entry.
+	path := c.path(entry.URL)
+	name := filepath.Base(path)
+	if err := writeFileAtomically(path, data); err != nil {
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+
+	// Index the entry, and pick the least recently used entries to evict while the cache is too large.
+	var evicted []string
+	c.mutex.Lock()
+	c.remove(name)
+	c.files[name] = c.order.PushFront(cacheFile{name: name, size: int64(len(data))})
+	c.size += int64(len(data))
+	for c.size > c.maxSize {
+		file := c.order.Back().Value.(cacheFile)
+		c.remove(file.name)
+		evicted = append(evicted, file.name)
+	}
+	c.mutex.Unlock()
+
+	for _, name := range evicted {
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not evict cache entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// forget removes a file from the index.
+func (c *Cache) forget(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.remove(name)
+}
+
+// remove removes a file from the index. The mutex must be held.
+func (c *Cache) remove(name string) {
+	if element, found := c.files[name]; found {
+		c.size -= c.order.Remove(element).(cacheFile).size
+		delete(c.files, name)
+	}
+}
+
+// writeFileAtomically writes the data to a temporary file of its own, renamed to the path once written,
+// so that concurrent writers of the same path do not interleave.
+func writeFileAtomically(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// path returns the path of the file of the URL's entry.
+func (c *Cache) path(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+cacheFileExtension)
+}
+
+// freshUntil computes until when a response can be used without revalidation, from its Cache-Control
+// and Expires headers. Responses without these headers are fresh for the TTL of the cache, and the
+// freshness of a response never exceeds the TTL.
+//
+// Parameters:
+//   - cacheControl: The Cache-Control header of the response.
+//   - expires: The Expires header of the response.
+//   - now: The time the response was received.
+//
+// Returns:
+//   - time.Time: The time until which the response is fresh.
+//   - bool: False if the response must not be stored (Cache-Control: no-store).
+func (c *Cache) freshUntil(cacheControl string, expires string, now time.Time) (time.Time, bool) {
+	limit := now.Add(c.ttl)
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.ToLower(strings.TrimSpace(directive)), "=")
+		switch name {
+		case "no-store":
+			return time.Time{}, false
+		case "no-cache":
+			return now, true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				return minTime(now.Add(time.Duration(seconds)*time.Second), limit), true
+			}
+		}
+	}
+
+	if expires != "" {
+		if expiresAt, err := http.ParseTime(expires); err == nil {
+			return minTime(expiresAt, limit), true
+		}
+		// An invalid Expires header means that the response is already expired.
+		return now, true
+	}
+
+	return limit, true
+}
+
+// minTime returns the earlier of two times.
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
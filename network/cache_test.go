@@ -0,0 +1,276 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	cache, err := OpenCache(t.TempDir(), WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error opening cache: %v", err)
+	}
+
+	now := time.Now()
+	entries := []CacheEntry{
+		{URL: "https://example.com/fresh", ETag: `"v1"`, StoredAt: now, FreshUntil: now.Add(time.Minute), Body: "fresh"},
+		{URL: "https://example.com/stale", StoredAt: now, FreshUntil: now, Body: "stale"},
+		{URL: "https://example.com/expired", StoredAt: now.Add(-2 * time.Hour), FreshUntil: now, Body: "expired"},
+	}
+	for _, entry := range entries {
+		if err := cache.Put(entry); err != nil {
+			t.Fatalf("unexpected error storing %v: %v", entry.URL, err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		url           string
+		expectedFound bool
+		expectedFresh bool
+		expectedBody  string
+	}{
+		{name: "Fresh entry", url: "https://example.com/fresh", expectedFound: true, expectedFresh: true, expectedBody: "fresh"},
+		{name: "Stale entry", url: "https://example.com/stale", expectedFound: true, expectedFresh: false, expectedBody: "stale"},
+		{name: "Entry older than the TTL", url: "https://example.com/expired", expectedFound: false},
+		{name: "Missing entry", url: "https://example.com/missing", expectedFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, found := cache.Get(tt.url)
+			if found != tt.expectedFound {
+				t.Fatalf("expected found: %v, got: %v", tt.expectedFound, found)
+			}
+			if !found {
+				return
+			}
+			if entry.Fresh(time.Now()) != tt.expectedFresh {
+				t.Errorf("expected fresh: %v, got entry %+v", tt.expectedFresh, entry)
+			}
+			if entry.Body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, entry.Body)
+			}
+		})
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	body := strings.Repeat("x", 1000)
+
+	// Each entry takes a little more than 1000 bytes, so only two fit.
+	cache, err := OpenCache(dir, WithMaxSize(2500))
+	if err != nil {
+		t.Fatalf("unexpected error opening cache: %v", err)
+	}
+
+	for i, url := range []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"} {
+		if err := cache.Put(CacheEntry{URL: url, StoredAt: time.Now(), Body: body}); err != nil {
+			t.Fatalf("unexpected error storing %v: %v", url, err)
+		}
+		// Use the first entry, so that it is not the least recently used.
+		if i == 1 {
+			cache.Get("https://example.com/1")
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+cacheFileExtension))
+	if len(files) != 2 {
+		t.Errorf("expected 2 entries after eviction, got %d", len(files))
+	}
+	if _, found := cache.Get("https://example.com/2"); found {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, found := cache.Get("https://example.com/1"); !found {
+		t.Error("expected the recently used entry to be kept")
+	}
+}
+
+func TestOpenCacheIndex(t *testing.T) {
+	dir := t.TempDir()
+	body := strings.Repeat("x", 1000)
+	cache, err := OpenCache(dir)
+	if err != nil {
+		t.Fatalf("unexpected error opening cache: %v", err)
+	}
+	urls := []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"}
+	for i, url := range urls {
+		if err := cache.Put(CacheEntry{URL: url, StoredAt: time.Now(), Body: body}); err != nil {
+			t.Fatalf("unexpected error storing %v: %v", url, err)
+		}
+		// The order of the next runs is the order of the modification times: 2, then 1, then 3.
+		used := time.Now().Add(time.Duration([]int{-20, -30, -10}[i]) * time.Second)
+		os.Chtimes(cache.path(url), used, used)
+	}
+
+	// A smaller cache opened on the same directory indexes its files, and evicts the least recently used.
+	cache, err = OpenCache(dir, WithMaxSize(2500))
+	if err != nil {
+		t.Fatalf("unexpected error opening cache: %v", err)
+	}
+	var size int64
+	for _, url := range urls {
+		info, _ := os.Stat(cache.path(url))
+		size += info.Size()
+	}
+	if cache.order.Len() != 3 || cache.size != size {
+		t.Fatalf("expected the 3 files of %v bytes in the index, got %v files of %v bytes", size, cache.order.Len(), cache.size)
+	}
+	if err := cache.Put(CacheEntry{URL: "https://example.com/4", StoredAt: time.Now(), Body: body}); err != nil {
+		t.Fatalf("unexpected error storing: %v", err)
+	}
+	for _, tt := range []struct {
+		url           string
+		expectedFound bool
+	}{
+		{url: "https://example.com/1", expectedFound: false},
+		{url: "https://example.com/2", expectedFound: false},
+		{url: "https://example.com/3", expectedFound: true},
+		{url: "https://example.com/4", expectedFound: true},
+	} {
+		if _, found := cache.Get(tt.url); found != tt.expectedFound {
+			t.Errorf("expected %v found: %v, got: %v", tt.url, tt.expectedFound, found)
+		}
+	}
+}
+
+func TestCacheConcurrentPut(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := OpenCache(dir, WithMaxSize(5000))
+	if err != nil {
+		t.Fatalf("unexpected error opening cache: %v", err)
+	}
+
+	// Concurrent writers of the same and of different URLs keep the index in line with the files.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			url := fmt.Sprintf("https://example.com/%d", i%10)
+			if err := cache.Put(CacheEntry{URL: url, StoredAt: time.Now(), Body: strings.Repeat("x", 500)}); err != nil {
+				t.Errorf("unexpected error storing %v: %v", url, err)
+			}
+			cache.Get(url)
+		}(i)
+	}
+	wg.Wait()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+cacheFileExtension))
+	var size int64
+	for _, file := range files {
+		info, _ := os.Stat(file)
+		size += info.Size()
+	}
+	if len(files) != cache.order.Len() || size != cache.size || size > 5000 {
+		t.Errorf("expected the index to match the %v files of %v bytes, got %v files of %v bytes", len(files), size, cache.order.Len(), cache.size)
+	}
+	if temporaryFiles, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(temporaryFiles) != 0 {
+		t.Errorf("expected no temporary files, got %v", temporaryFiles)
+	}
+}
+
+func TestFreshUntil(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := &Cache{ttl: time.Hour}
+
+	tests := []struct {
+		name          string
+		cacheControl  string
+		expires       string
+		expectedFresh time.Time
+		expectedStore bool
+	}{
+		{name: "No headers", expectedFresh: now.Add(time.Hour), expectedStore: true},
+		{name: "Max age", cacheControl: "public, max-age=60", expectedFresh: now.Add(time.Minute), expectedStore: true},
+		{name: "Max age above TTL", cacheControl: "max-age=86400", expectedFresh: now.Add(time.Hour), expectedStore: true},
+		{name: "No cache", cacheControl: "no-cache", expectedFresh: now, expectedStore: true},
+		{name: "No store", cacheControl: "private, no-store", expectedStore: false},
+		{name: "Expires", expires: "Mon, 01 Jan 2024 12:30:00 GMT", expectedFresh: now.Add(30 * time.Minute), expectedStore: true},
+		{name: "Max age overrides expires", cacheControl: "max-age=10", expires: "Mon, 01 Jan 2024 12:30:00 GMT", expectedFresh: now.Add(10 * time.Second), expectedStore: true},
+		{name: "Invalid expires", expires: "0", expectedFresh: now, expectedStore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fresh, store := cache.freshUntil(tt.cacheControl, tt.expires, now)
+			if store != tt.expectedStore {
+				t.Fatalf("expected store: %v, got: %v", tt.expectedStore, store)
+			}
+			if store && !fresh.Equal(tt.expectedFresh) {
+				t.Errorf("expected fresh until %v, got %v", tt.expectedFresh, fresh)
+			}
+		})
+	}
+}
+
+// conditionalClient is a mock HTTP client serving a page with an ETag, which answers conditional
+// requests with 304 Not Modified and counts the full responses.
+type conditionalClient struct {
+	cacheControl  string
+	requests      int
+	fullResponses int
+}
+
+func (c *conditionalClient) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	c.requests++
+	resp.Header.Set("ETag", `"v1"`)
+	resp.Header.Set("Cache-Control", c.cacheControl)
+	if string(req.Header.Peek("If-None-Match")) == `"v1"` {
+		resp.SetStatusCode(fasthttp.StatusNotModified)
+		return nil
+	}
+	c.fullResponses++
+	resp.SetStatusCode(fasthttp.StatusOK)
+	resp.SetBody([]byte("page content"))
+	return nil
+}
+
+func TestFetchContentWithCache(t *testing.T) {
+	tests := []struct {
+		name                  string
+		cacheControl          string
+		refresh               bool
+		expectedRequests      int
+		expectedFullResponses int
+	}{
+		{name: "Fresh pages are served from the cache", cacheControl: "max-age=3600", expectedRequests: 1, expectedFullResponses: 1},
+		{name: "Stale pages are revalidated", cacheControl: "no-cache", expectedRequests: 3, expectedFullResponses: 1},
+		{name: "Refresh fetches the pages again", cacheControl: "max-age=3600", refresh: true, expectedRequests: 3, expectedFullResponses: 3},
+		{name: "Uncacheable pages are fetched again", cacheControl: "no-store", expectedRequests: 3, expectedFullResponses: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := OpenCache(t.TempDir())
+			if err != nil {
+				t.Fatalf("unexpected error opening cache: %v", err)
+			}
+			client := &conditionalClient{cacheControl: tt.cacheControl}
+			fetcher := NewFetcher(WithHTTPClient(client), WithCache(cache), WithRefresh(tt.refresh))
+
+			for i := 0; i < 3; i++ {
+				body, err := fetcher.FetchContent("https://example.com")
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if body != "page content" {
+					t.Errorf("expected the page content, got %q", body)
+				}
+			}
+
+			if client.requests != tt.expectedRequests || client.fullResponses != tt.expectedFullResponses {
+				t.Errorf("expected %d requests and %d full responses, got %d and %d",
+					tt.expectedRequests, tt.expectedFullResponses, client.requests, client.fullResponses)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Errors returned by FetchContent, to be checked with errors.Is.
+var (
+	ErrBlocked          = errors.New("blocked by the endpoint server")
+	ErrNotFound         = errors.New("page not found")
+	ErrTimeout          = errors.New("request timed out")
+	ErrTooManyRetries   = errors.New("too many retries")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrNoLocation       = errors.New("redirect with no Location header")
+)
+
+// StatusBlocked is the non-standard status code returned by servers that block the client.
+const StatusBlocked = 999
+
+// StatusError is returned when the server responds with a status code other than OK or a redirect.
+// It matches ErrNotFound for status 404 and ErrBlocked for StatusBlocked, so that errors.Is can be used
+// without inspecting the status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	if e.StatusCode == StatusBlocked {
+		return fmt.Sprintf("blocked by the endpoint server with status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("received non-200 response: %d", e.StatusCode)
+}
+
+// Is reports whether the status code of the error corresponds to the target sentinel error.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == fasthttp.StatusNotFound
+	case ErrBlocked:
+		return e.StatusCode == StatusBlocked
+	}
+	return false
+}
+
+// wrapRequestError wraps an error of the HTTP client with ErrTimeout if the request timed out.
+func wrapRequestError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, fasthttp.ErrTimeout) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
@@ -3,12 +3,19 @@
 package network
 
 import (
-	"firefly-assignment/config"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/valyala/fasthttp"
 )
 
+// Default settings of a Fetcher.
+const (
+	DefaultMaxRetries   = 3
+	DefaultMaxRedirects = 5
+)
+
 // Creating an abstraction over fasthttp.Client to allow for mocking in test.
 // HTTPClient defines the interface for an HTTP client
 type HTTPClient interface {
@@ -24,13 +31,132 @@ func (c *DefaultHTTPClient) Do(req *fasthttp.Request, resp *fasthttp.Response) e
 	return c.Client.Do(req, resp)
 }
 
-var httpClient HTTPClient = &DefaultHTTPClient{
-	Client: &fasthttp.Client{
-		ReadBufferSize: 8192, // Increase buffer size to handle larger request header sizes.
-	},
+// newDefaultHTTPClient creates the HTTP client used when no client is configured.
+func newDefaultHTTPClient() HTTPClient {
+	return &DefaultHTTPClient{
+		Client: &fasthttp.Client{
+			ReadBufferSize: 8192, // Increase buffer size to handle larger request header sizes.
+		},
+	}
+}
+
+// Archiver archives the raw HTTP exchanges of a Fetcher, e.g. to a WARC file.
+type Archiver interface {
+	Archive(url string, request []byte, response []byte) error
+}
+
+// Observer is notified of the requests of a Fetcher, e.g. to export metrics. It must be safe for concurrent use.
+type Observer interface {
+	// RequestStarted is called before each request, including redirects and retries.
+	RequestStarted()
+	// RequestFinished is called after each request, with the size of the response body in bytes.
+	RequestFinished(bytes int)
+	// Retried is called before a failed request is retried.
+	Retried()
+	// Redirected is called before a redirect is followed.
+	Redirected()
+	// FetchFinished is called once a URL is fetched or has failed, with the time taken by all its requests.
+	FetchFinished(duration time.Duration)
+}
+
+// Fetcher retrieves the content of URLs, handling retries and redirects, and optionally caching the pages.
+type Fetcher struct {
+	httpClient   HTTPClient
+	maxRetries   int
+	maxRedirects int
+	cache        *Cache
+	refresh      bool
+	archiver     Archiver
+	observer     Observer
+	dials        *dialTracker
+}
+
+// FetcherOption configures a Fetcher.
+type FetcherOption func(f *Fetcher)
+
+// WithHTTPClient sets the HTTP client used to make the requests.
+func WithHTTPClient(client HTTPClient) FetcherOption {
+	return func(f *Fetcher) { f.httpClient = client }
+}
+
+// WithMaxRetries sets the maximum number of retries for failed requests.
+func WithMaxRetries(maxRetries int) FetcherOption {
+	return func(f *Fetcher) { f.maxRetries = maxRetries }
+}
+
+// WithMaxRedirects sets the maximum number of redirects that are followed per request.
+func WithMaxRedirects(maxRedirects int) FetcherOption {
+	return func(f *Fetcher) { f.maxRedirects = maxRedirects }
+}
+
+// WithCache sets the cache of fetched pages. Cached pages are served without a request while they are fresh,
+// and revalidated with a conditional request once they are stale.
+func WithCache(cache *Cache) FetcherOption {
+	return func(f *Fetcher) { f.cache = cache }
+}
+
+// WithRefresh sets whether cached pages are ignored and fetched again, refreshing the cache.
+func WithRefresh(refresh bool) FetcherOption {
+	return func(f *Fetcher) { f.refresh = refresh }
+}
+
+// WithArchiver sets the archiver of the raw requests and responses, including redirects and retries.
+// Pages served from the cache are not requested, and therefore not archived.
+func WithArchiver(archiver Archiver) FetcherOption {
+	return func(f *Fetcher) { f.archiver = archiver }
+}
+
+// WithObserver sets the observer of the requests. Pages served from the cache are not requested, and therefore not observed.
+func WithObserver(observer Observer) FetcherOption {
+	return func(f *Fetcher) { f.observer = observer }
+}
+
+// WithDialTracing sets whether the default HTTP client dials its connections with separate timings of the DNS
+// lookup and the TCP connection, which FetchContentTraced records.
+func WithDialTracing(enabled bool) FetcherOption {
+	return func(f *Fetcher) {
+		f.dials = nil
+		if enabled {
+			f.dials = newDialTracker()
+		}
+	}
+}
+
+// NewFetcher creates a Fetcher with the default settings, overridden by the given options.
+//
+// Parameters:
+//   - opts: The options to apply to the Fetcher.
+//
+// Returns:
+//   - *Fetcher: The configured Fetcher.
+func NewFetcher(opts ...FetcherOption) *Fetcher {
+	f := &Fetcher{
+		httpClient:   newDefaultHTTPClient(),
+		maxRetries:   DefaultMaxRetries,
+		maxRedirects: DefaultMaxRedirects,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if client, ok := f.httpClient.(*DefaultHTTPClient); ok && f.dials != nil {
+		client.Client.Dial = f.dials.dial
+	}
+	return f
+}
+
+// response holds the parts of an HTTP response that are used by the Fetcher.
+type response struct {
+	body         string
+	etag         string
+	lastModified string
+	cacheControl string
+	expires      string
+	notModified  bool
 }
 
 // FetchContent retrieves the content from the given URL, handling retries and redirects.
+// If the Fetcher has a cache, fresh cached pages are returned without a request, stale ones are
+// revalidated with a conditional request, and fetched pages are stored in the cache.
 //
 // Parameters:
 //   - url: The URL to fetch content from.
@@ -38,13 +164,87 @@ var httpClient HTTPClient = &DefaultHTTPClient{
 // Returns:
 //   - string: The response body as a string if the request succeeds.
 //   - error: An error if the request fails, exceeds retries, or encounters too many redirects.
-func FetchContent(url string) (string, error) {
-	maxRetries := config.AppConfig.MaxRetries
-	maxRedirects := config.AppConfig.MaxRedirects
+//     The error matches one of the Err* sentinel errors or is a *StatusError.
+func (f *Fetcher) FetchContent(url string) (string, error) {
+	return f.FetchContentTraced(url, nil)
+}
 
+// FetchContentTraced retrieves the content from the given URL like FetchContent, and records the stages
+// of each request, including redirects and retries: the DNS lookup and TCP connection of new connections
+// (see WithDialTracing), the time to the response headers and the time to read the body. Pages served from
+// the cache are not requested, and therefore not recorded.
+//
+// Parameters:
+//   - url: The URL to fetch content from.
+//   - recorder: The recorder of the stages, or nil.
+//
+// Returns:
+//   - string: The response body as a string if the request succeeds.
+//   - error: An error if the request fails, exceeds retries, or encounters too many redirects.
+func (f *Fetcher) FetchContentTraced(url string, recorder StageRecorder) (string, error) {
+	if f.cache == nil {
+		resp, err := f.fetch(url, nil, recorder)
+		return resp.body, err
+	}
+
+	cached, found := f.cache.Get(url)
+	if found && !f.refresh && cached.Fresh(time.Now()) {
+		slog.Debug("Serving URL from cache", "url", url)
+		return cached.Body, nil
+	}
+
+	var validators *CacheEntry
+	if found && !f.refresh {
+		validators = &cached
+	}
+	resp, err := f.fetch(url, validators, recorder)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	entry := CacheEntry{URL: url, ETag: resp.etag, LastModified: resp.lastModified, StoredAt: now, Body: resp.body}
+	if resp.notModified {
+		// The cached page is still valid; the server may omit the validators in a 304 response.
+		slog.Debug("Revalidated cached URL", "url", url)
+		entry.Body = cached.Body
+		if entry.ETag == "" {
+			entry.ETag = cached.ETag
+		}
+		if entry.LastModified == "" {
+			entry.LastModified = cached.LastModified
+		}
+	}
+
+	if freshUntil, store := f.cache.freshUntil(resp.cacheControl, resp.expires, now); store {
+		entry.FreshUntil = freshUntil
+		if err := f.cache.Put(entry); err != nil {
+			slog.Warn("Could not cache URL", "url", url, "error", err)
+		}
+	}
+
+	return entry.Body, nil
+}
+
+// fetch requests the given URL, handling retries and redirects. If validators are given, the request
+// is conditional, and a 304 Not Modified response is returned with notModified set.
+//
+// Parameters:
+//   - url: The URL to fetch content from.
+//   - validators: The cached entry whose ETag and Last-Modified validators are sent, or nil.
+//   - recorder: The recorder of the stages of the requests, or nil.
+//
+// Returns:
+//   - response: The body and the caching headers of the response.
+//   - error: An error if the request fails, exceeds retries, or encounters too many redirects.
+func (f *Fetcher) fetch(url string, validators *CacheEntry, recorder StageRecorder) (response, error) {
 	var redirectCount int = 0
 	var retryCount int = 0
 
+	if f.observer != nil {
+		defer func(start time.Time) { f.observer.FetchFinished(time.Since(start)) }(time.Now())
+	}
+
 	// Handle the edge-case where the URL could be a redirect.
 	// Attempt to discover the redirected URL and fetch the content from there.
 	for {
@@ -57,49 +257,106 @@ func FetchContent(url string) (string, error) {
 		// Set the URL for the request
 		req.SetRequestURI(url)
 
-		// Make the GET request
-		err := httpClient.Do(req, resp)
+		// The validators only apply to the URL of the cached entry, not to the URLs it redirects to.
+		if validators != nil && redirectCount == 0 {
+			if validators.ETag != "" {
+				req.Header.Set("If-None-Match", validators.ETag)
+			}
+			if validators.LastModified != "" {
+				req.Header.Set("If-Modified-Since", validators.LastModified)
+			}
+		}
+
+		// Make the GET request. When its stages are recorded, the body is streamed, so that the request returns
+		// once the response headers are received, and the body is read separately.
+		if f.observer != nil {
+			f.observer.RequestStarted()
+		}
+		start := time.Now()
+		resp.StreamBody = recorder != nil
+		err := f.httpClient.Do(req, resp)
+		if err == nil && recorder != nil {
+			err = f.recordStages(recorder, resp, start, time.Now())
+		}
+		if f.observer != nil {
+			f.observer.RequestFinished(len(resp.Body()))
+		}
+		if err == nil && f.archiver != nil {
+			if archiveErr := f.archiver.Archive(url, []byte(req.String()), []byte(resp.String())); archiveErr != nil {
+				slog.Warn("Could not archive URL", "url", url, "error", archiveErr)
+			}
+		}
 
 		statusCode := resp.StatusCode()
 
-		if statusCode == 999 {
-			return "", fmt.Errorf("[ERROR] - blocked by the endpoint server with status code 999")
+		if statusCode == StatusBlocked {
+			return response{}, &StatusError{URL: url, StatusCode: statusCode}
 		}
 
-		if statusCode == 404 || err != nil {
-			if retryCount >= maxRetries {
-				return "", fmt.Errorf("[ERROR] - too many retries")
+		if statusCode == fasthttp.StatusNotFound || err != nil {
+			var requestErr error = &StatusError{URL: url, StatusCode: statusCode}
+			if err != nil {
+				requestErr = wrapRequestError(err)
+			}
+
+			if retryCount >= f.maxRetries {
+				return response{}, fmt.Errorf("%w: %w", ErrTooManyRetries, requestErr)
 			}
 
-			fmt.Println("[WARN] - Retrying URL:" + url)
 			retryCount++
+			if f.observer != nil {
+				f.observer.Retried()
+			}
+			slog.Warn("Retrying URL", "url", url, "attempt", retryCount, "error", requestErr)
 			continue
 		}
 
+		// A conditional request was answered with 304 Not Modified: the cached page is still valid
+		if statusCode == fasthttp.StatusNotModified && validators != nil && redirectCount == 0 {
+			r := responseHeaders(resp)
+			r.notModified = true
+			return r, nil
+		}
+
 		// Check if it's a redirect status code (301, 302, 303, 307, 308)
 		if statusCode >= 300 && statusCode < 400 {
-			if redirectCount >= maxRedirects {
-				return "", fmt.Errorf("[ERROR] - too many redirects")
+			if redirectCount >= f.maxRedirects {
+				return response{}, ErrTooManyRedirects
 			}
 
 			// Get the "Location" header to find the new URL
 			newURL := resp.Header.Peek("Location")
 			if newURL == nil {
-				return "", fmt.Errorf("[ERROR] - redirect with no Location header")
+				return response{}, ErrNoLocation
 			}
 
 			// Update the URL to the new location and continue the loop
 			url = string(newURL)
 			redirectCount++
+			if f.observer != nil {
+				f.observer.Redirected()
+			}
 			continue
 		}
 
 		// Return the response body as a string if it's not a redirect
 		if statusCode == fasthttp.StatusOK {
-			return string(resp.Body()), nil
+			r := responseHeaders(resp)
+			r.body = string(resp.Body())
+			return r, nil
 		}
 
 		// If the status code is not OK or a redirect, return an error
-		return "", fmt.Errorf("[ERROR] - received non-200 response: %d", statusCode)
+		return response{}, &StatusError{URL: url, StatusCode: statusCode}
+	}
+}
+
+// responseHeaders returns the caching headers of a response.
+func responseHeaders(resp *fasthttp.Response) response {
+	return response{
+		etag:         string(resp.Header.Peek("ETag")),
+		lastModified: string(resp.Header.Peek("Last-Modified")),
+		cacheControl: string(resp.Header.Peek("Cache-Control")),
+		expires:      string(resp.Header.Peek("Expires")),
 	}
 }
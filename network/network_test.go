@@ -1,9 +1,11 @@
 package network
 
 import (
-	"firefly-assignment/config"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/valyala/fasthttp"
 )
@@ -31,14 +33,13 @@ func (m *mockClient) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
 }
 
 func TestFetchContent(t *testing.T) {
-	config.LoadConfig()
-
 	tests := []struct {
 		name          string
 		mockClient    *mockClient
 		url           string
 		expectedBody  string
 		expectedError bool
+		expectedIs    []error
 	}{
 		{
 			name: "Valid content fetch",
@@ -59,6 +60,17 @@ func TestFetchContent(t *testing.T) {
 			url:           "http://example.com",
 			expectedBody:  "",
 			expectedError: true,
+			expectedIs:    []error{ErrTooManyRedirects},
+		},
+		{
+			name: "Redirect without location",
+			mockClient: &mockClient{
+				statusCode: fasthttp.StatusFound,
+			},
+			url:           "http://example.com",
+			expectedBody:  "",
+			expectedError: true,
+			expectedIs:    []error{ErrNoLocation},
 		},
 		{
 			name: "Server blocking with status code 999",
@@ -68,6 +80,7 @@ func TestFetchContent(t *testing.T) {
 			url:           "http://blocked.com",
 			expectedBody:  "",
 			expectedError: true,
+			expectedIs:    []error{ErrBlocked},
 		},
 		{
 			name: "Retry limit reached",
@@ -77,6 +90,26 @@ func TestFetchContent(t *testing.T) {
 			url:           "http://retry.com",
 			expectedBody:  "",
 			expectedError: true,
+			expectedIs:    []error{ErrTooManyRetries, ErrNotFound},
+		},
+		{
+			name: "Server error",
+			mockClient: &mockClient{
+				statusCode: fasthttp.StatusInternalServerError,
+			},
+			url:           "http://error.com",
+			expectedBody:  "",
+			expectedError: true,
+		},
+		{
+			name: "Timeout",
+			mockClient: &mockClient{
+				err: fasthttp.ErrTimeout,
+			},
+			url:           "http://slow.com",
+			expectedBody:  "",
+			expectedError: true,
+			expectedIs:    []error{ErrTooManyRetries, ErrTimeout, fasthttp.ErrTimeout},
 		},
 		{
 			name: "Network error",
@@ -86,22 +119,30 @@ func TestFetchContent(t *testing.T) {
 			url:           "http://network-error.com",
 			expectedBody:  "",
 			expectedError: true,
+			expectedIs:    []error{ErrTooManyRetries},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Replace the default fasthttp client with the mock client
-			httpClient = tt.mockClient
+			fetcher := NewFetcher(WithHTTPClient(tt.mockClient))
 
 			// Call the function being tested
-			body, err := FetchContent(tt.url)
+			body, err := fetcher.FetchContent(tt.url)
 
 			// Check if the error matches the expectation
 			if (err != nil) != tt.expectedError {
 				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
 			}
 
+			// Check that the error can be classified
+			for _, target := range tt.expectedIs {
+				if !errors.Is(err, target) {
+					t.Errorf("expected error %v to match %v", err, target)
+				}
+			}
+
 			// Check if the returned body matches the expected body
 			if body != tt.expectedBody {
 				t.Errorf("expected body: %v, got: %v", tt.expectedBody, body)
@@ -109,3 +150,112 @@ func TestFetchContent(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		expectedIs  error
+		expectedNot error
+	}{
+		{name: "Not found", statusCode: fasthttp.StatusNotFound, expectedIs: ErrNotFound, expectedNot: ErrBlocked},
+		{name: "Blocked", statusCode: StatusBlocked, expectedIs: ErrBlocked, expectedNot: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed to fetch URL: %w", &StatusError{URL: "http://example.com", StatusCode: tt.statusCode})
+			if !errors.Is(err, tt.expectedIs) {
+				t.Errorf("expected %v to match %v", err, tt.expectedIs)
+			}
+			if errors.Is(err, tt.expectedNot) {
+				t.Errorf("expected %v not to match %v", err, tt.expectedNot)
+			}
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.statusCode {
+				t.Errorf("expected a StatusError with status %d, got %v", tt.statusCode, err)
+			}
+		})
+	}
+}
+
+// mockArchiver records the URLs and raw responses it archives.
+type mockArchiver struct {
+	urls      []string
+	responses []string
+}
+
+func (a *mockArchiver) Archive(url string, request []byte, response []byte) error {
+	a.urls = append(a.urls, url)
+	a.responses = append(a.responses, string(response))
+	return nil
+}
+
+func TestFetchContentArchive(t *testing.T) {
+	archiver := &mockArchiver{}
+	fetcher := NewFetcher(WithHTTPClient(&mockClient{statusCode: fasthttp.StatusOK, body: "archived body"}), WithArchiver(archiver))
+
+	if _, err := fetcher.FetchContent("http://example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(archiver.urls) != 1 || archiver.urls[0] != "http://example.com" {
+		t.Fatalf("expected one archived exchange for http://example.com, got %v", archiver.urls)
+	}
+	if !strings.HasPrefix(archiver.responses[0], "HTTP/1.1 200 OK") || !strings.HasSuffix(archiver.responses[0], "archived body") {
+		t.Errorf("expected the raw response to be archived, got %q", archiver.responses[0])
+	}
+}
+
+// mockObserver counts the observed requests.
+type mockObserver struct {
+	started   int
+	finished  int
+	bytes     int
+	retries   int
+	redirects int
+	fetches   int
+}
+
+func (o *mockObserver) RequestStarted()                      { o.started++ }
+func (o *mockObserver) RequestFinished(bytes int)            { o.finished++; o.bytes += bytes }
+func (o *mockObserver) Retried()                             { o.retries++ }
+func (o *mockObserver) Redirected()                          { o.redirects++ }
+func (o *mockObserver) FetchFinished(duration time.Duration) { o.fetches++ }
+
+func TestFetchContentObserver(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockClient *mockClient
+		expected   mockObserver
+	}{
+		{
+			name:       "Fetched page",
+			mockClient: &mockClient{statusCode: fasthttp.StatusOK, body: "12345"},
+			expected:   mockObserver{started: 1, finished: 1, bytes: 5, fetches: 1},
+		},
+		{
+			name:       "Retried page",
+			mockClient: &mockClient{statusCode: fasthttp.StatusNotFound, body: "404"},
+			expected:   mockObserver{started: 3, finished: 3, bytes: 9, retries: 2, fetches: 1},
+		},
+		{
+			name:       "Redirected page",
+			mockClient: &mockClient{statusCode: fasthttp.StatusFound, redirect: "http://redirect.com"},
+			expected:   mockObserver{started: 2, finished: 2, redirects: 1, fetches: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer := &mockObserver{}
+			fetcher := NewFetcher(WithHTTPClient(tt.mockClient), WithMaxRetries(2), WithMaxRedirects(1), WithObserver(observer))
+			fetcher.FetchContent("http://example.com")
+
+			if *observer != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *observer)
+			}
+		})
+	}
+}
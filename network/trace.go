@@ -0,0 +1,188 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Stages of a request that are recorded by FetchContentTraced.
+const (
+	StageDNS      = "dns"       // DNS lookup of a new connection, unless cached
+	StageConnect  = "connect"   // TCP connection of a new connection
+	StageTTFB     = "ttfb"      // From the request, or the new connection, to the response headers
+	StageBodyRead = "body_read" // Reading of the response body
+)
+
+// StageRecorder records the timed stages of the requests of a URL, e.g. as tracing spans.
+type StageRecorder interface {
+	Record(name string, start time.Time, end time.Time)
+}
+
+// dialTiming holds the timing of a dialed connection, until the request that dialed it claims it.
+type dialTiming struct {
+	resolved     bool // Whether the host was looked up, rather than found in the DNS cache
+	dnsStart     time.Time
+	dnsEnd       time.Time
+	connectStart time.Time
+	connected    time.Time
+	claimed      atomic.Bool
+}
+
+// timedAddr is the local address of a dialed connection, which carries the timing of the connection.
+// The client hands the local address of a connection over to its responses, so that the request that
+// dialed the connection finds its timing there.
+type timedAddr struct {
+	net.Addr
+	timing *dialTiming
+}
+
+// timedConn is a dialed connection whose local address carries its timing.
+type timedConn struct {
+	net.Conn
+	localAddr *timedAddr
+}
+
+// LocalAddr returns the local address of the connection, with its timing.
+func (c *timedConn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+// dnsEntry is a cached DNS lookup.
+type dnsEntry struct {
+	ips     []net.IPAddr
+	expires time.Time
+}
+
+// dialTracker dials the connections of the default HTTP client with separate timings for the DNS lookup
+// and the TCP connection, attached to each connection. The lookups are cached as long as by the default
+// dialer of the client (fasthttp.DefaultDNSCacheDuration), so that a traced job resolves its hosts as often as others.
+type dialTracker struct {
+	mutex    sync.Mutex
+	dnsCache map[string]dnsEntry
+}
+
+// newDialTracker creates a dialTracker with an empty DNS cache.
+func newDialTracker() *dialTracker {
+	return &dialTracker{dnsCache: make(map[string]dnsEntry)}
+}
+
+// dial resolves the host of the address, and connects to its first reachable IP address.
+func (d *dialTracker) dial(addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	timing := &dialTiming{}
+	ips, err := d.lookup(host, timing)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: fasthttp.DefaultDialTimeout}
+	timing.connectStart = time.Now()
+	for _, ip := range ips {
+		conn, dialErr := dialer.Dial("tcp", net.JoinHostPort(ip.String(), port))
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+		timing.connected = time.Now()
+		return &timedConn{Conn: conn, localAddr: &timedAddr{Addr: conn.LocalAddr(), timing: timing}}, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("no IP address for host %v", host)
+	}
+	return nil, err
+}
+
+// lookup returns the IP addresses of a host from the DNS cache, or looks them up, recording the timing
+// of the lookup. IP addresses are not looked up.
+func (d *dialTracker) lookup(host string, timing *dialTiming) ([]net.IPAddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+
+	now := time.Now()
+	d.mutex.Lock()
+	entry, cached := d.dnsCache[host]
+	d.mutex.Unlock()
+	if cached && now.Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fasthttp.DefaultDialTimeout)
+	defer cancel()
+	timing.resolved = true
+	timing.dnsStart = now
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	timing.dnsEnd = time.Now()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	// Expired entries are removed on each lookup, so that the cache only holds the hosts of the last minutes.
+	for cachedHost, cachedEntry := range d.dnsCache {
+		if !now.Before(cachedEntry.expires) {
+			delete(d.dnsCache, cachedHost)
+		}
+	}
+	d.dnsCache[host] = dnsEntry{ips: ips, expires: timing.dnsEnd.Add(fasthttp.DefaultDNSCacheDuration)}
+	return ips, nil
+}
+
+// claimDial returns the timing of the connection of a response if it was dialed by dialTracker and not claimed yet,
+// that is if the connection was dialed for the request of the response.
+func claimDial(resp *fasthttp.Response) (*dialTiming, bool) {
+	addr, ok := resp.LocalAddr().(*timedAddr)
+	if !ok || addr.timing.claimed.Swap(true) {
+		return nil, false
+	}
+	return addr.timing, true
+}
+
+// recordStages reads the streamed body of a response, and records the stages of its request:
+// the DNS lookup (unless cached) and TCP connection if the request dialed a new connection, the time to the response
+// headers, and the time to read the body.
+//
+// Parameters:
+//   - recorder: The recorder of the stages.
+//   - resp: The response, whose body is streamed.
+//   - start: When the request was made.
+//   - headers: When the response headers were received.
+//
+// Returns:
+//   - error: An error if the body cannot be read.
+func (f *Fetcher) recordStages(recorder StageRecorder, resp *fasthttp.Response, start time.Time, headers time.Time) error {
+	timing, dialed := claimDial(resp)
+
+	if stream := resp.BodyStream(); stream != nil {
+		body, err := io.ReadAll(stream)
+		resp.CloseBodyStream()
+		if err != nil {
+			return fmt.Errorf("could not read the response body: %w", err)
+		}
+		resp.SetBody(body)
+	}
+	bodyRead := time.Now()
+
+	if dialed {
+		if timing.resolved {
+			recorder.Record(StageDNS, timing.dnsStart, timing.dnsEnd)
+		}
+		recorder.Record(StageConnect, timing.connectStart, timing.connected)
+		start = timing.connected
+	}
+	recorder.Record(StageTTFB, start, headers)
+	recorder.Record(StageBodyRead, headers, bodyRead)
+	return nil
+}
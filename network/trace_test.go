@@ -0,0 +1,117 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockRecorder records the names of the stages.
+type mockRecorder struct {
+	stages []string
+}
+
+func (r *mockRecorder) Record(name string, start time.Time, end time.Time) {
+	if end.Before(start) {
+		name += " (negative)"
+	}
+	r.stages = append(r.stages, name)
+}
+
+func TestFetchContentTraced(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://"+r.Host+"/page", http.StatusFound)
+			return
+		case "/close":
+			w.Header().Set("Connection", "close")
+		}
+		fmt.Fprint(w, "traced body")
+	}))
+	defer server.Close()
+	// A host name, rather than an IP address, is looked up.
+	serverURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	fetcher := NewFetcher(WithDialTracing(true))
+	tests := []struct {
+		name           string
+		url            string
+		expectedStages []string
+	}{
+		{
+			name:           "New connection",
+			url:            serverURL + "/page",
+			expectedStages: []string{StageDNS, StageConnect, StageTTFB, StageBodyRead},
+		},
+		{
+			name:           "Reused connection",
+			url:            serverURL + "/page",
+			expectedStages: []string{StageTTFB, StageBodyRead},
+		},
+		{
+			name:           "Redirect",
+			url:            serverURL + "/redirect",
+			expectedStages: []string{StageTTFB, StageBodyRead, StageTTFB, StageBodyRead},
+		},
+		{
+			name:           "Connection closed by the server",
+			url:            serverURL + "/close",
+			expectedStages: []string{StageTTFB, StageBodyRead},
+		},
+		{
+			name:           "New connection to a cached host",
+			url:            serverURL + "/page",
+			expectedStages: []string{StageConnect, StageTTFB, StageBodyRead},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &mockRecorder{}
+			body, err := fetcher.FetchContentTraced(tt.url, recorder)
+			if err != nil || body != "traced body" {
+				t.Fatalf("expected the body, got %q, %v", body, err)
+			}
+			if !reflect.DeepEqual(recorder.stages, tt.expectedStages) {
+				t.Errorf("expected stages %v, got %v", tt.expectedStages, recorder.stages)
+			}
+		})
+	}
+}
+
+func TestDialTrackerLookup(t *testing.T) {
+	tracker := newDialTracker()
+	tracker.dnsCache["expired.example"] = dnsEntry{expires: time.Now().Add(-time.Second)}
+
+	tests := []struct {
+		name             string
+		host             string
+		expectedResolved bool
+	}{
+		{name: "IP address", host: "127.0.0.1", expectedResolved: false},
+		{name: "Host name", host: "localhost", expectedResolved: true},
+		{name: "Cached host name", host: "localhost", expectedResolved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timing := &dialTiming{}
+			ips, err := tracker.lookup(tt.host, timing)
+			if err != nil || len(ips) == 0 {
+				t.Fatalf("expected the IP addresses, got %v, %v", ips, err)
+			}
+			if timing.resolved != tt.expectedResolved {
+				t.Errorf("expected resolved %v, got %v", tt.expectedResolved, timing.resolved)
+			}
+		})
+	}
+
+	if _, exists := tracker.dnsCache["expired.example"]; exists {
+		t.Error("expected the expired entry to be removed from the DNS cache")
+	}
+}
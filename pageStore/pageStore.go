@@ -0,0 +1,149 @@
+/*
+Package pageStore persists fetched pages on disk, so that they can be analyzed later without
+fetching them again. Each page is stored in its own file named after the SHA-256 hash of its URL,
+and an index file maps the URLs to the stored pages.
+*/
+package pageStore
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// indexFileName is the name of the index file that maps the URLs to the stored pages.
+const indexFileName = "index.tsv"
+
+// Store is a directory of stored pages.
+type Store struct {
+	dir   string
+	mutex sync.Mutex
+	urls  []string
+	pages map[string]string
+}
+
+// Open opens the page store in the given directory, creating the directory if it does not exist.
+//
+// Parameters:
+//   - dir: The directory of the page store.
+//
+// Returns:
+//   - *Store: The opened page store.
+//   - error: An error if the directory cannot be created or the index cannot be read.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create page store directory: %w", err)
+	}
+
+	store := &Store{dir: dir, pages: make(map[string]string)}
+	file, err := os.Open(filepath.Join(dir, indexFileName))
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open page store index: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fileName, url, found := strings.Cut(scanner.Text(), "\t")
+		if !found {
+			continue
+		}
+		if _, exists := store.pages[url]; !exists {
+			store.urls = append(store.urls, url)
+		}
+		store.pages[url] = fileName
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read page store index: %w", err)
+	}
+
+	return store, nil
+}
+
+// Save stores the body of the page fetched from the given URL, replacing any previously stored body.
+// It is safe to call Save concurrently.
+//
+// Parameters:
+//   - url: The URL the page was fetched from.
+//   - body: The body of the page.
+//
+// Returns:
+//   - error: An error if the page or the index cannot be written.
+func (s *Store) Save(url string, body string) error {
+	fileName := pageFileName(url)
+	if err := os.WriteFile(filepath.Join(s.dir, fileName), []byte(body), 0644); err != nil {
+		return fmt.Errorf("could not write page: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.pages[url]; exists {
+		return nil
+	}
+
+	index, err := os.OpenFile(filepath.Join(s.dir, indexFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open page store index: %w", err)
+	}
+	defer index.Close()
+
+	if _, err := fmt.Fprintf(index, "%s\t%s\n", fileName, url); err != nil {
+		return fmt.Errorf("could not write page store index: %w", err)
+	}
+
+	s.urls = append(s.urls, url)
+	s.pages[url] = fileName
+	return nil
+}
+
+// URLs returns the URLs of the stored pages, in the order they were first stored.
+//
+// Returns:
+//   - []string: The URLs of the stored pages.
+func (s *Store) URLs() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.urls...)
+}
+
+// Load returns the stored body of the page fetched from the given URL.
+//
+// Parameters:
+//   - url: The URL the page was fetched from.
+//
+// Returns:
+//   - string: The body of the page.
+//   - error: An error if the page is not stored or cannot be read.
+func (s *Store) Load(url string) (string, error) {
+	s.mutex.Lock()
+	fileName, exists := s.pages[url]
+	s.mutex.Unlock()
+
+	if !exists {
+		return "", fmt.Errorf("page not found in store: %v", url)
+	}
+
+	body, err := os.ReadFile(filepath.Join(s.dir, fileName))
+	if err != nil {
+		return "", fmt.Errorf("could not read page: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// pageFileName returns the name of the file that stores the page fetched from the given URL.
+func pageFileName(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(hash[:]) + ".html"
+}
@@ -0,0 +1,74 @@
+package pageStore
+
+import (
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %v", err)
+	}
+
+	pages := []struct {
+		url  string
+		body string
+	}{
+		{url: "https://example.com/a", body: "<html>first</html>"},
+		{url: "https://example.com/b", body: "<html>second</html>"},
+		{url: "https://example.com/a", body: "<html>updated</html>"},
+	}
+	for _, page := range pages {
+		if err := store.Save(page.url, page.body); err != nil {
+			t.Fatalf("unexpected error saving %v: %v", page.url, err)
+		}
+	}
+
+	// Reopen the store to check that the index is persisted.
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("unexpected error reopening store: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		url           string
+		expectedBody  string
+		expectedError bool
+	}{
+		{
+			name:         "Stored page",
+			url:          "https://example.com/b",
+			expectedBody: "<html>second</html>",
+		},
+		{
+			name:         "Overwritten page",
+			url:          "https://example.com/a",
+			expectedBody: "<html>updated</html>",
+		},
+		{
+			name:          "Missing page",
+			url:           "https://example.com/missing",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := reopened.Load(tt.url)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("expected body: %v, got: %v", tt.expectedBody, body)
+			}
+		})
+	}
+
+	urls := reopened.URLs()
+	if len(urls) != 2 || urls[0] != "https://example.com/a" || urls[1] != "https://example.com/b" {
+		t.Errorf("expected URLs [https://example.com/a https://example.com/b], got %v", urls)
+	}
+}
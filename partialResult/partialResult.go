@@ -0,0 +1,151 @@
+/*
+Package partialResult provides a serializable format for the partial results of a run: the word and
+entity counts, the document frequencies of the words and the statistics of the run. A large job can be
+split across processes or machines, each writing a partial result, and the partial results merged into
+one whose top words and TF-IDF scores are the same as if a single process had run the whole job.
+*/
+package partialResult
+
+import (
+	"encoding/json"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"firefly-assignment/wordOps"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// FormatVersion is the version of the partial result format. Partial results of other versions cannot be merged.
+const FormatVersion = 1
+
+// Result is the partial result of a run.
+type Result struct {
+	Version             int                              `json:"version"`
+	Documents           int                              `json:"documents"`
+	Totals              runSummary.Totals                `json:"totals"`
+	Errors              map[string]runSummary.ErrorCount `json:"errors"`
+	Languages           map[string]int32                 `json:"languages"`
+	Counts              utils.WordFrequencyMap           `json:"counts"`
+	Entities            utils.WordFrequencyMap           `json:"entities"`
+	DocumentFrequencies utils.WordFrequencyMap           `json:"document_frequencies"`
+	Articles            []runSummary.Article             `json:"articles"`
+}
+
+// New creates an empty partial result, to merge other partial results into.
+//
+// Returns:
+//   - *Result: The empty partial result.
+func New() *Result {
+	return &Result{
+		Version:             FormatVersion,
+		Errors:              make(map[string]runSummary.ErrorCount),
+		Languages:           make(map[string]int32),
+		Counts:              make(utils.WordFrequencyMap),
+		Entities:            make(utils.WordFrequencyMap),
+		DocumentFrequencies: make(utils.WordFrequencyMap),
+		Articles:            []runSummary.Article{},
+	}
+}
+
+// Merge adds the counts, document frequencies and statistics of another partial result to the result.
+// The articles are kept sorted by URL, so that the merged result does not depend on the order of the merges.
+//
+// Parameters:
+//   - other: The partial result to add.
+func (r *Result) Merge(other *Result) {
+	r.Documents += other.Documents
+	r.Totals.Total += other.Totals.Total
+	r.Totals.Processed += other.Totals.Processed
+	r.Totals.Errored += other.Totals.Errored
+
+	for class, errorCount := range other.Errors {
+		r.Errors[class] = r.Errors[class].Add(errorCount)
+	}
+	for language, count := range other.Languages {
+		r.Languages[language] += count
+	}
+	wordOps.MergeFrequencies(r.Counts, other.Counts)
+	wordOps.MergeFrequencies(r.Entities, other.Entities)
+	wordOps.MergeFrequencies(r.DocumentFrequencies, other.DocumentFrequencies)
+
+	r.Articles = append(r.Articles, other.Articles...)
+	sort.SliceStable(r.Articles, func(i, j int) bool { return r.Articles[i].URL < r.Articles[j].URL })
+}
+
+// Write writes the partial result to the writer as JSON.
+//
+// Parameters:
+//   - w: The writer to write the partial result to.
+//
+// Returns:
+//   - error: An error if the partial result cannot be converted to JSON or written.
+func (r *Result) Write(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(r); err != nil {
+		return fmt.Errorf("could not convert partial result to JSON: %w", err)
+	}
+	return nil
+}
+
+// Save writes the partial result as JSON to the file at the given path, replacing the file if it exists.
+//
+// Parameters:
+//   - path: The path of the file.
+//
+// Returns:
+//   - error: An error if the file cannot be written.
+func (r *Result) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create partial result file: %w", err)
+	}
+	defer file.Close()
+
+	if err := r.Write(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Read reads a partial result from the reader.
+//
+// Parameters:
+//   - reader: The reader to read the JSON partial result from.
+//
+// Returns:
+//   - *Result: The partial result.
+//   - error: An error if the partial result is not valid JSON or has an unsupported format version.
+func Read(reader io.Reader) (*Result, error) {
+	result := New()
+	result.Version = 0
+	if err := json.NewDecoder(reader).Decode(result); err != nil {
+		return nil, fmt.Errorf("could not parse partial result: %w", err)
+	}
+	if result.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported partial result version %v, expected %v", result.Version, FormatVersion)
+	}
+	return result, nil
+}
+
+// Load reads the partial result from the file at the given path.
+//
+// Parameters:
+//   - path: The path of the file.
+//
+// Returns:
+//   - *Result: The partial result.
+//   - error: An error if the file cannot be read or does not hold a valid partial result.
+func Load(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open partial result file: %w", err)
+	}
+	defer file.Close()
+
+	result, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package partialResult
+
+import (
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	first := &Result{
+		Version:             FormatVersion,
+		Documents:           2,
+		Totals:              runSummary.Totals{Total: 3, Processed: 2, Errored: 1},
+		Errors:              map[string]runSummary.ErrorCount{runSummary.ClassNotFound: {Count: 1, ExampleURLs: []string{"https://example.com/missing"}}},
+		Languages:           map[string]int32{"en": 2},
+		Counts:              utils.WordFrequencyMap{"apple": 3, "phone": 1},
+		Entities:            utils.WordFrequencyMap{"Apple": 2},
+		DocumentFrequencies: utils.WordFrequencyMap{"apple": 2, "phone": 1},
+		Articles:            []runSummary.Article{{URL: "https://example.com/c"}, {URL: "https://example.com/a"}},
+	}
+	second := &Result{
+		Version:             FormatVersion,
+		Documents:           1,
+		Totals:              runSummary.Totals{Total: 2, Processed: 1, Errored: 1},
+		Errors:              map[string]runSummary.ErrorCount{runSummary.ClassTimeout: {Count: 1, ExampleURLs: []string{"https://example.com/slow"}}},
+		Languages:           map[string]int32{"de": 1},
+		Counts:              utils.WordFrequencyMap{"apple": 1, "haus": 2},
+		DocumentFrequencies: utils.WordFrequencyMap{"apple": 1, "haus": 1},
+		Articles:            []runSummary.Article{{URL: "https://example.com/b"}},
+	}
+	expected := &Result{
+		Version:   FormatVersion,
+		Documents: 3,
+		Totals:    runSummary.Totals{Total: 5, Processed: 3, Errored: 2},
+		Errors: map[string]runSummary.ErrorCount{
+			runSummary.ClassNotFound: {Count: 1, ExampleURLs: []string{"https://example.com/missing"}},
+			runSummary.ClassTimeout:  {Count: 1, ExampleURLs: []string{"https://example.com/slow"}},
+		},
+		Languages:           map[string]int32{"en": 2, "de": 1},
+		Counts:              utils.WordFrequencyMap{"apple": 4, "phone": 1, "haus": 2},
+		Entities:            utils.WordFrequencyMap{"Apple": 2},
+		DocumentFrequencies: utils.WordFrequencyMap{"apple": 3, "phone": 1, "haus": 1},
+		Articles:            []runSummary.Article{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}, {URL: "https://example.com/c"}},
+	}
+
+	tests := []struct {
+		name    string
+		results []*Result
+	}{
+		{name: "In order", results: []*Result{first, second}},
+		{name: "In reverse order", results: []*Result{second, first}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := New()
+			for _, result := range tt.results {
+				merged.Merge(result)
+			}
+			if !reflect.DeepEqual(merged, expected) {
+				t.Errorf("expected %+v, got %+v", expected, merged)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	result := New()
+	result.Documents = 1
+	result.Totals = runSummary.Totals{Total: 1, Processed: 1}
+	result.Languages["en"] = 1
+	result.Counts["apple"] = 2
+	result.DocumentFrequencies["apple"] = 1
+	result.Articles = append(result.Articles, runSummary.Article{URL: "https://example.com/a", Language: "en", Words: 4, CountedWords: 2})
+
+	path := filepath.Join(t.TempDir(), "partial.json")
+	if err := result.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, result) {
+		t.Errorf("expected %+v, got %+v", result, loaded)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{name: "Not JSON", contents: "counts", expectedError: "could not parse partial result"},
+		{name: "Missing version", contents: `{"documents": 1}`, expectedError: "unsupported partial result version 0"},
+		{name: "Newer version", contents: `{"version": 2}`, expectedError: "unsupported partial result version 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.contents))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(os.TempDir(), "missing-partial.json")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Package pipeline counts the words of articles, the same way for every command and for the jobs of the serve command:
+it extracts the article of a page, counts its words (and its entities, in languages without capitalized nouns)
+with the word bank of its language, and accumulates the counts, document frequencies and languages of a run.
+*/
+package pipeline
+
+import (
+	"firefly-assignment/article"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"firefly-assignment/wordOps"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ArticleTopWords is the number of top words of each article in the run summary.
+const ArticleTopWords = 5
+
+// Result holds the counts of an article. Published is the zero time if the publication date is unknown.
+type Result struct {
+	URL       string
+	Language  string
+	Words     int
+	Published time.Time
+	Counts    utils.WordFrequencyMap
+	Entities  utils.WordFrequencyMap
+}
+
+// Pipeline extracts the articles of pages and counts their words.
+type Pipeline struct {
+	extractor *article.Extractor
+	wordBanks utils.LanguageWordBanks
+}
+
+// New creates a Pipeline.
+//
+// Parameters:
+//   - extractor: The extractor of the articles.
+//   - wordBanks: The word banks of valid words, keyed by language.
+//
+// Returns:
+//   - *Pipeline: The Pipeline.
+func New(extractor *article.Extractor, wordBanks utils.LanguageWordBanks) *Pipeline {
+	return &Pipeline{extractor: extractor, wordBanks: wordBanks}
+}
+
+// Extract extracts the article of a page.
+//
+// Parameters:
+//   - body: The HTML body of the page.
+//
+// Returns:
+//   - article.Article: The article.
+//   - error: An error if the page has no article.
+func (p *Pipeline) Extract(body string) (article.Article, error) {
+	content, err := p.extractor.GetArticle(body)
+	if err != nil {
+		return article.Article{}, fmt.Errorf("failed to extract article content: %w", err)
+	}
+	return content, nil
+}
+
+// Count counts the words of an article with the word bank of its language. In languages without
+// capitalized nouns, the named entities are counted apart from the words.
+//
+// Parameters:
+//   - url: The URL (or name) of the article.
+//   - content: The article.
+//
+// Returns:
+//   - Result: The counts of the article.
+func (p *Pipeline) Count(url string, content article.Article) Result {
+	published, _ := article.PublishedDate(url, content)
+	result := Result{
+		URL:       url,
+		Language:  content.Language,
+		Words:     len(content.Words),
+		Published: published,
+		Counts:    make(utils.WordFrequencyMap),
+		Entities:  make(utils.WordFrequencyMap),
+	}
+	if article.HasCapitalizedNouns(content.Language) {
+		wordOps.CountWords(content.Words, p.WordBank(content.Language), result.Counts)
+	} else {
+		wordOps.CountWordsAndEntities(content.Words, p.WordBank(content.Language), result.Counts, result.Entities)
+	}
+	return result
+}
+
+// WordBank returns the word bank of the given language, falling back to the default word bank
+// so that a misdetected article is still counted as before.
+//
+// Parameters:
+//   - language: The language of an article.
+//
+// Returns:
+//   - utils.WordBank: The word bank of the language.
+func (p *Pipeline) WordBank(language string) utils.WordBank {
+	if wordBank, exists := p.wordBanks[language]; exists {
+		return wordBank
+	}
+	return p.wordBanks[article.DefaultLanguage]
+}
+
+// Totals accumulates the counts of the articles of a run: the word and entity frequencies, the number of
+// articles containing each word (for TF-IDF), and the number of articles of each language.
+// The maps may be read directly once the run is done.
+type Totals struct {
+	Words               utils.WordFrequencyMap
+	Entities            utils.WordFrequencyMap
+	DocumentFrequencies utils.WordFrequencyMap
+	Languages           map[string]int32
+
+	mutex sync.Mutex
+}
+
+// NewTotals creates empty totals.
+//
+// Returns:
+//   - *Totals: The totals.
+func NewTotals() *Totals {
+	return &Totals{
+		Words:               make(utils.WordFrequencyMap),
+		Entities:            make(utils.WordFrequencyMap),
+		DocumentFrequencies: make(utils.WordFrequencyMap),
+		Languages:           make(map[string]int32),
+	}
+}
+
+// Add merges the counts of an article into the totals, and returns the entry of the article in the run summary.
+// It is safe to call Add concurrently.
+//
+// Parameters:
+//   - result: The counts of the article.
+//
+// Returns:
+//   - runSummary.Article: The entry of the article in the run summary.
+func (t *Totals) Add(result Result) runSummary.Article {
+	t.mutex.Lock()
+	wordOps.MergeFrequencies(t.Words, result.Counts)
+	wordOps.MergeFrequencies(t.Entities, result.Entities)
+	wordOps.CountDocument(t.DocumentFrequencies, result.Counts)
+	t.Languages[result.Language]++
+	t.mutex.Unlock()
+
+	var countedWords int
+	for _, count := range result.Counts {
+		countedWords += int(count)
+	}
+	return runSummary.Article{
+		URL:          result.URL,
+		Language:     result.Language,
+		Words:        result.Words,
+		CountedWords: countedWords,
+		TopWords:     wordOps.GetTopNWords(ArticleTopWords, result.Counts),
+	}
+}
+
+// Top completes the summary with the top n words, entities and TF-IDF scores of the totals, and with
+// a copy of the languages. It is safe to call Top concurrently with Add.
+//
+// Parameters:
+//   - n: The number of top results.
+//   - summary: The summary of the run, whose articles are the documents of the TF-IDF scores.
+func (t *Totals) Top(n int, summary *runSummary.Summary) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	languages := make(map[string]int32, len(t.Languages))
+	for language, count := range t.Languages {
+		languages[language] = count
+	}
+	summary.Languages = languages
+	summary.TopWords = wordOps.GetTopNWords(n, t.Words)
+	summary.TopEntities = wordOps.GetTopNEntities(n, t.Entities)
+	summary.TopTFIDF = wordOps.GetTopNTFIDF(n, t.Words, t.DocumentFrequencies, len(summary.Articles))
+}
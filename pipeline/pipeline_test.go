@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"firefly-assignment/wordOps"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestPipeline() *Pipeline {
+	return New(article.NewExtractor(), utils.LanguageWordBanks{
+		article.DefaultLanguage: {"phone": {}, "great": {}},
+		"de":                    {"haus": {}},
+	})
+}
+
+func TestExtract(t *testing.T) {
+	p := newTestPipeline()
+
+	content, err := p.Extract(`<html><body><div class="caas-body">The phone is great.</div></body></html>`)
+	if err != nil || len(content.Words) != 4 {
+		t.Errorf("expected the 4 words of the article, got %v (error %v)", content.Words, err)
+	}
+	if _, err := p.Extract(`<html><body><p>No article here.</p></body></html>`); !errors.Is(err, article.ErrNoContent) {
+		t.Errorf("expected ErrNoContent, got %v", err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	p := newTestPipeline()
+
+	tests := []struct {
+		name             string
+		url              string
+		content          article.Article
+		expectedCounts   utils.WordFrequencyMap
+		expectedEntities utils.WordFrequencyMap
+		expectedDate     time.Time
+	}{
+		{
+			name:             "Words and entities",
+			url:              "https://example.com/2019/08/25/phone",
+			content:          article.Article{Language: "en", Words: []string{"The", "great", "phone", "of", "Tim", "Cook."}},
+			expectedCounts:   utils.WordFrequencyMap{"great": 1, "phone": 1},
+			expectedEntities: utils.WordFrequencyMap{"Tim Cook": 1},
+			expectedDate:     time.Date(2019, 8, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "Language with capitalized nouns",
+			url:              "text#1",
+			content:          article.Article{Language: "de", Words: []string{"Das", "Haus"}},
+			expectedCounts:   utils.WordFrequencyMap{"haus": 1},
+			expectedEntities: utils.WordFrequencyMap{},
+		},
+		{
+			name:             "Language without a word bank",
+			url:              "text#2",
+			content:          article.Article{Language: "fr", Words: []string{"phone"}},
+			expectedCounts:   utils.WordFrequencyMap{"phone": 1},
+			expectedEntities: utils.WordFrequencyMap{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := p.Count(tt.url, tt.content)
+			if result.URL != tt.url || result.Language != tt.content.Language || result.Words != len(tt.content.Words) {
+				t.Errorf("expected the URL, language and words of the article, got %+v", result)
+			}
+			if !reflect.DeepEqual(result.Counts, tt.expectedCounts) || !reflect.DeepEqual(result.Entities, tt.expectedEntities) {
+				t.Errorf("expected counts %v and entities %v, got %v and %v", tt.expectedCounts, tt.expectedEntities, result.Counts, result.Entities)
+			}
+			if !result.Published.Equal(tt.expectedDate) {
+				t.Errorf("expected the publication date %v, got %v", tt.expectedDate, result.Published)
+			}
+		})
+	}
+}
+
+func TestTotals(t *testing.T) {
+	totals := NewTotals()
+	summary := runSummary.New("firefly run", nil)
+	for _, result := range []Result{
+		{URL: "https://example.com/a", Language: "en", Words: 5, Counts: utils.WordFrequencyMap{"phone": 2, "great": 1}, Entities: utils.WordFrequencyMap{"Tim Cook": 1}},
+		{URL: "https://example.com/b", Language: "en", Words: 2, Counts: utils.WordFrequencyMap{"phone": 1}},
+		{URL: "text#1", Language: "de", Words: 2, Counts: utils.WordFrequencyMap{"haus": 1}},
+	} {
+		summary.AddArticle(totals.Add(result))
+	}
+
+	expectedArticle := runSummary.Article{URL: "https://example.com/a", Language: "en", Words: 5, CountedWords: 3,
+		TopWords: []utils.WordFreq{{Word: "phone", Frequency: 2}, {Word: "great", Frequency: 1}}}
+	if !reflect.DeepEqual(summary.Articles[0], expectedArticle) {
+		t.Errorf("expected the summary entry %+v, got %+v", expectedArticle, summary.Articles[0])
+	}
+	if !reflect.DeepEqual(totals.DocumentFrequencies, utils.WordFrequencyMap{"phone": 2, "great": 1, "haus": 1}) {
+		t.Errorf("expected the document frequencies, got %v", totals.DocumentFrequencies)
+	}
+
+	totals.Top(1, summary)
+	if !reflect.DeepEqual(summary.TopWords, []utils.WordFreq{{Word: "phone", Frequency: 3}}) {
+		t.Errorf("expected the top word, got %v", summary.TopWords)
+	}
+	if !reflect.DeepEqual(summary.TopEntities, []utils.WordFreq{{Word: "Tim Cook", Frequency: 1}}) {
+		t.Errorf("expected the top entity, got %v", summary.TopEntities)
+	}
+	if expected := wordOps.GetTopNTFIDF(1, totals.Words, totals.DocumentFrequencies, 3); !reflect.DeepEqual(summary.TopTFIDF, expected) {
+		t.Errorf("expected the top word by TF-IDF over the 3 articles %v, got %v", expected, summary.TopTFIDF)
+	}
+
+	// The languages of the summary are a copy, which later articles do not change.
+	totals.Add(Result{URL: "text#2", Language: "de", Counts: utils.WordFrequencyMap{}})
+	if !reflect.DeepEqual(summary.Languages, map[string]int32{"en": 2, "de": 1}) {
+		t.Errorf("expected the languages of the articles, got %v", summary.Languages)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Package progress reports the progress of a running job. On a terminal, it redraws a single status line
+with the live counts, throughput, ETA and rate limit; otherwise it logs the same status periodically,
+so that redirected output is not flooded with control characters.
+*/
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Default intervals between two updates of the progress.
+const (
+	DefaultInteractiveInterval = 200 * time.Millisecond
+	DefaultLogInterval         = 10 * time.Second
+)
+
+// Stats is a snapshot of the counters of a running job.
+type Stats struct {
+	Total     int
+	Processed int
+	Errored   int
+	InFlight  int
+	RateLimit float64
+}
+
+// Done returns the number of entries that are finished, successfully or not.
+func (s Stats) Done() int {
+	return s.Processed + s.Errored
+}
+
+// Reporter periodically writes the progress of a job, read from a stats function.
+type Reporter struct {
+	w           io.Writer
+	stats       func() Stats
+	logger      *slog.Logger
+	interactive bool
+	interval    time.Duration
+	start       time.Time
+	stop        chan struct{}
+	done        sync.WaitGroup
+}
+
+// Option configures a Reporter.
+type Option func(r *Reporter)
+
+// WithInteractive sets whether the progress is redrawn in place on a single line, rather than written as log lines.
+func WithInteractive(interactive bool) Option {
+	return func(r *Reporter) { r.interactive = interactive }
+}
+
+// WithLogger sets the logger used to log the progress when it is not redrawn in place.
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Reporter) { r.logger = logger }
+}
+
+// WithInterval sets the interval between two updates of the progress.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Reporter) { r.interval = interval }
+}
+
+// New creates a Reporter that writes the progress to the given writer. By default the progress is
+// redrawn in place if the writer is a terminal, and logged with the default logger otherwise.
+//
+// Parameters:
+//   - w: The writer to write the progress to.
+//   - stats: A function returning the current counters of the job. It is called from another goroutine.
+//   - opts: The options to apply to the Reporter.
+//
+// Returns:
+//   - *Reporter: The configured Reporter, to be started with Start.
+func New(w io.Writer, stats func() Stats, opts ...Option) *Reporter {
+	r := &Reporter{w: w, stats: stats, logger: slog.Default(), interactive: IsTerminal(w)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.interval == 0 {
+		r.interval = DefaultLogInterval
+		if r.interactive {
+			r.interval = DefaultInteractiveInterval
+		}
+	}
+	return r
+}
+
+// Interactive returns whether the progress is redrawn in place on a single line.
+func (r *Reporter) Interactive() bool {
+	return r.interactive
+}
+
+// Start starts writing the progress periodically, until Stop is called.
+func (r *Reporter) Start() {
+	r.start = time.Now()
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.write()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops writing the progress. In interactive mode, the final status is drawn and the line is ended.
+func (r *Reporter) Stop() {
+	close(r.stop)
+	r.done.Wait()
+
+	if r.interactive {
+		r.write()
+		fmt.Fprintln(r.w)
+	}
+}
+
+// write writes the current status, either redrawing the status line or as a log message.
+func (r *Reporter) write() {
+	stats, elapsed := r.stats(), time.Since(r.start)
+	if r.interactive {
+		// Return to the start of the line and clear it before redrawing.
+		fmt.Fprintf(r.w, "\r\033[K%v", Format(stats, elapsed))
+		return
+	}
+
+	throughput, eta := estimate(stats, elapsed)
+	r.logger.Info("Progress",
+		"done", stats.Done(),
+		"total", stats.Total,
+		"errored", stats.Errored,
+		"in_flight", stats.InFlight,
+		"urls_per_second", fmt.Sprintf("%.1f", throughput),
+		"eta", eta,
+		"rate_limit", stats.RateLimit,
+	)
+}
+
+// Format formats the status of a job: the finished, errored and in-flight entries, the throughput,
+// the estimated time until the job is finished and the current rate limit.
+//
+// Parameters:
+//   - stats: The counters of the job.
+//   - elapsed: The time since the job started.
+//
+// Returns:
+//   - string: The status of the job.
+func Format(stats Stats, elapsed time.Duration) string {
+	throughput, eta := estimate(stats, elapsed)
+
+	percentage := 100.0
+	if stats.Total > 0 {
+		percentage = 100 * float64(stats.Done()) / float64(stats.Total)
+	}
+
+	return fmt.Sprintf("%d/%d (%.0f%%) done, %d errored, %d in flight, %.1f URLs/s, ETA %v, limit %v req/s",
+		stats.Done(), stats.Total, percentage, stats.Errored, stats.InFlight, throughput, eta, stats.RateLimit)
+}
+
+// estimate returns the throughput of a job in entries per second, and the estimated time until it is finished.
+func estimate(stats Stats, elapsed time.Duration) (float64, string) {
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(stats.Done()) / elapsed.Seconds()
+	}
+
+	eta := "unknown"
+	if remaining := stats.Total - stats.Done(); remaining <= 0 {
+		eta = "0s"
+	} else if throughput > 0 {
+		eta = time.Duration(float64(remaining) / throughput * float64(time.Second)).Round(time.Second).String()
+	}
+
+	return throughput, eta
+}
+
+// IsTerminal reports whether the writer is a terminal (a character device), rather than a file or a pipe.
+//
+// Parameters:
+//   - w: The writer.
+//
+// Returns:
+//   - bool: True if the writer is a terminal.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,105 @@
+package progress
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    Stats
+		elapsed  time.Duration
+		expected string
+	}{
+		{
+			name:     "Not started",
+			stats:    Stats{Total: 10, RateLimit: 20},
+			elapsed:  0,
+			expected: "0/10 (0%) done, 0 errored, 0 in flight, 0.0 URLs/s, ETA unknown, limit 20 req/s",
+		},
+		{
+			name:     "Halfway",
+			stats:    Stats{Total: 100, Processed: 40, Errored: 10, InFlight: 5, RateLimit: 20},
+			elapsed:  10 * time.Second,
+			expected: "50/100 (50%) done, 10 errored, 5 in flight, 5.0 URLs/s, ETA 10s, limit 20 req/s",
+		},
+		{
+			name:     "Finished",
+			stats:    Stats{Total: 4, Processed: 4, RateLimit: 2.5},
+			elapsed:  2 * time.Second,
+			expected: "4/4 (100%) done, 0 errored, 0 in flight, 2.0 URLs/s, ETA 0s, limit 2.5 req/s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := Format(tt.stats, tt.elapsed); status != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, status)
+			}
+		})
+	}
+}
+
+// syncBuilder is a strings.Builder that can be written and read concurrently.
+type syncBuilder struct {
+	mutex   sync.Mutex
+	builder strings.Builder
+}
+
+func (b *syncBuilder) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.builder.Write(p)
+}
+
+func (b *syncBuilder) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.builder.String()
+}
+
+func TestReporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		interactive bool
+		expected    string
+		unexpected  string
+	}{
+		{name: "Log messages", interactive: false, expected: "level=INFO msg=Progress done=1 total=2", unexpected: "\r"},
+		{name: "Interactive", interactive: true, expected: "\r\033[K1/2 (50%) done", unexpected: "msg=Progress"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output syncBuilder
+			stats := func() Stats { return Stats{Total: 2, Processed: 1} }
+
+			logger := slog.New(slog.NewTextHandler(&output, nil))
+			reporter := New(&output, stats, WithInteractive(tt.interactive), WithLogger(logger), WithInterval(5*time.Millisecond))
+			reporter.Start()
+			time.Sleep(30 * time.Millisecond)
+			reporter.Stop()
+
+			if !strings.Contains(output.String(), tt.expected) {
+				t.Errorf("expected output to contain %q, got %q", tt.expected, output.String())
+			}
+			if strings.Contains(output.String(), tt.unexpected) {
+				t.Errorf("expected output not to contain %q, got %q", tt.unexpected, output.String())
+			}
+		})
+	}
+}
+
+func TestNewDetectsNonTerminal(t *testing.T) {
+	reporter := New(&strings.Builder{}, func() Stats { return Stats{} })
+	if reporter.Interactive() {
+		t.Error("expected a non-terminal writer to use log lines")
+	}
+	if reporter.interval != DefaultLogInterval {
+		t.Errorf("expected the default log interval, got %v", reporter.interval)
+	}
+}
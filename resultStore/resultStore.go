@@ -0,0 +1,375 @@
+/*
+Package resultStore persists the results of runs in an embedded bbolt database, so that they can be
+queried and exported again across runs without fetching anything.
+
+The database has one top-level bucket, "runs", with a nested bucket per run keyed by the run ID
+(an 8-byte big-endian sequence number, so that runs are ordered by creation). Each run bucket holds:
+
+	summary   JSON of the runSummary.Summary of the run (configuration, timings, totals, error counts,
+	          languages, top words and entities, and the results of each article)
+	words     bucket of word -> count (4-byte big-endian) of all the articles
+	entities  bucket of entity -> count (4-byte big-endian) of all the articles
+	articles  bucket of sequence number -> JSON {"url": URL, "counts": {word: count}, "entities": {entity: count}}
+	          of each article
+	errors    bucket of sequence number -> JSON {"url": URL, "class": error class, "error": message} of each failed URL
+
+The articles and errors are keyed by their 8-byte big-endian sequence number within the run, in the order they
+were recorded, rather than by URL: every entry is kept, so a URL listed twice, or a redirected URL and its final
+URL, each keep their own entry. Runs stored by older versions, keyed by URL, are still read.
+*/
+package resultStore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Names of the buckets and keys of the database (see the package documentation for the schema).
+var (
+	runsBucket     = []byte("runs")
+	summaryKey     = []byte("summary")
+	wordsBucket    = []byte("words")
+	entitiesBucket = []byte("entities")
+	articlesBucket = []byte("articles")
+	errorsBucket   = []byte("errors")
+)
+
+// openTimeout is how long Open waits for another process to release the database.
+const openTimeout = time.Second
+
+// ErrRunNotFound is returned when a run does not exist in the database.
+var ErrRunNotFound = errors.New("run not found")
+
+// ArticleCounts holds the word and entity counts of a single article.
+type ArticleCounts struct {
+	URL      string                 `json:"url"`
+	Counts   utils.WordFrequencyMap `json:"counts"`
+	Entities utils.WordFrequencyMap `json:"entities,omitempty"`
+}
+
+// URLError holds the error of a failed URL.
+type URLError struct {
+	URL   string `json:"url"`
+	Class string `json:"class"`
+	Error string `json:"error"`
+}
+
+// Run holds the results of a run: its summary, the word and entity counts, and the counts and errors of each URL,
+// in the order they were recorded.
+type Run struct {
+	ID       uint64
+	Summary  *runSummary.Summary
+	Words    utils.WordFrequencyMap
+	Entities utils.WordFrequencyMap
+	Articles []ArticleCounts
+	Errors   []URLError
+
+	mutex sync.Mutex
+}
+
+// NewRun creates the results of a run with the given summary, to be completed while the run progresses.
+//
+// Parameters:
+//   - summary: The summary of the run.
+//
+// Returns:
+//   - *Run: The results of the run.
+func NewRun(summary *runSummary.Summary) *Run {
+	return &Run{
+		Summary:  summary,
+		Words:    make(utils.WordFrequencyMap),
+		Entities: make(utils.WordFrequencyMap),
+	}
+}
+
+// AddArticle records the word and entity counts of an article, after those already recorded, even for the same URL.
+// It is safe to call AddArticle concurrently.
+//
+// Parameters:
+//   - url: The URL of the article.
+//   - counts: The word counts of the article.
+//   - entities: The entity counts of the article.
+func (r *Run) AddArticle(url string, counts utils.WordFrequencyMap, entities utils.WordFrequencyMap) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Articles = append(r.Articles, ArticleCounts{URL: url, Counts: counts, Entities: entities})
+}
+
+// AddError records the error of a failed URL, after those already recorded, even for the same URL.
+// It is safe to call AddError concurrently.
+//
+// Parameters:
+//   - url: The URL that failed.
+//   - err: The error of the URL.
+func (r *Run) AddError(url string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Errors = append(r.Errors, URLError{URL: url, Class: runSummary.ErrorClass(err), Error: err.Error()})
+}
+
+// Store is an embedded database of run results.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database at the given path, creating it if it does not exist.
+// Only one process can open a database at a time.
+//
+// Parameters:
+//   - path: The path of the database file.
+//
+// Returns:
+//   - *Store: The opened database, which must be closed.
+//   - error: An error if the database cannot be opened or is locked by another process.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("could not open database %v: %w", path, err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(runsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not initialize database %v: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save stores the results of a run under a new run ID, and sets the ID of the run.
+//
+// Parameters:
+//   - run: The results of the run, whose summary must be finished.
+//
+// Returns:
+//   - uint64: The ID of the stored run.
+//   - error: An error if the run cannot be encoded or stored.
+func (s *Store) Save(run *Run) (uint64, error) {
+	run.mutex.Lock()
+	defer run.mutex.Unlock()
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		id, err := runs.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		bucket, err := runs.CreateBucket(runKey(id))
+		if err != nil {
+			return err
+		}
+
+		summary, err := json.Marshal(run.Summary)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(summaryKey, summary); err != nil {
+			return err
+		}
+
+		if err := putCounts(bucket, wordsBucket, run.Words); err != nil {
+			return err
+		}
+		if err := putCounts(bucket, entitiesBucket, run.Entities); err != nil {
+			return err
+		}
+		if err := putJSON(bucket, articlesBucket, run.Articles); err != nil {
+			return err
+		}
+		if err := putJSON(bucket, errorsBucket, run.Errors); err != nil {
+			return err
+		}
+
+		run.ID = id
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not store run: %w", err)
+	}
+
+	return run.ID, nil
+}
+
+// Runs returns the stored runs, oldest first, with their summaries but without their counts and errors.
+//
+// Returns:
+//   - []*Run: The stored runs.
+//   - error: An error if a summary cannot be read.
+func (s *Store) Runs() ([]*Run, error) {
+	var runs []*Run
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).ForEachBucket(func(key []byte) error {
+			id := binary.BigEndian.Uint64(key)
+			summary, err := readSummary(tx.Bucket(runsBucket).Bucket(key))
+			if err != nil {
+				return fmt.Errorf("run %v: %w", id, err)
+			}
+			runs = append(runs, &Run{ID: id, Summary: summary})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Load returns the stored results of a run.
+//
+// Parameters:
+//   - id: The ID of the run.
+//
+// Returns:
+//   - *Run: The results of the run.
+//   - error: ErrRunNotFound if the run does not exist, or an error if it cannot be read.
+func (s *Store) Load(id uint64) (*Run, error) {
+	run := NewRun(nil)
+	run.ID = id
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(runsBucket).Bucket(runKey(id))
+		if bucket == nil {
+			return ErrRunNotFound
+		}
+
+		var err error
+		if run.Summary, err = readSummary(bucket); err != nil {
+			return err
+		}
+		if err := readCounts(bucket, wordsBucket, run.Words); err != nil {
+			return err
+		}
+		if err := readCounts(bucket, entitiesBucket, run.Entities); err != nil {
+			return err
+		}
+		if err := readJSON(bucket, articlesBucket, func(key []byte, article ArticleCounts) {
+			if article.URL == "" {
+				article.URL = string(key)
+			}
+			run.Articles = append(run.Articles, article)
+		}); err != nil {
+			return err
+		}
+		return readJSON(bucket, errorsBucket, func(key []byte, urlError URLError) {
+			if urlError.URL == "" {
+				urlError.URL = string(key)
+			}
+			run.Errors = append(run.Errors, urlError)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load run %v: %w", id, err)
+	}
+
+	return run, nil
+}
+
+// runKey returns the key of the bucket of the run with the given ID.
+func runKey(id uint64) []byte {
+	return sequenceKey(id)
+}
+
+// sequenceKey returns the 8-byte big-endian key of a sequence number, so that the keys sort in sequence order.
+func sequenceKey(sequence uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, sequence)
+	return key
+}
+
+// putCounts stores the counts in a nested bucket with the given name.
+func putCounts(parent *bolt.Bucket, name []byte, counts utils.WordFrequencyMap) error {
+	bucket, err := parent.CreateBucket(name)
+	if err != nil {
+		return err
+	}
+
+	for word, count := range counts {
+		value := make([]byte, 4)
+		binary.BigEndian.PutUint32(value, uint32(count))
+		if err := bucket.Put([]byte(word), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCounts reads the counts of the nested bucket with the given name into the given map.
+func readCounts(parent *bolt.Bucket, name []byte, counts utils.WordFrequencyMap) error {
+	bucket := parent.Bucket(name)
+	if bucket == nil {
+		return fmt.Errorf("missing bucket %q", name)
+	}
+
+	return bucket.ForEach(func(word, value []byte) error {
+		if len(value) != 4 {
+			return fmt.Errorf("invalid count of %q in bucket %q", word, name)
+		}
+		counts[string(word)] = int32(binary.BigEndian.Uint32(value))
+		return nil
+	})
+}
+
+// putJSON stores each value as JSON in a nested bucket with the given name, keyed by its sequence number from 1.
+func putJSON[T any](parent *bolt.Bucket, name []byte, values []T) error {
+	bucket, err := parent.CreateBucket(name)
+	if err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(sequenceKey(uint64(i+1)), encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readJSON reads the JSON values of the nested bucket with the given name in key order, and hands each over
+// with its key to the given function.
+func readJSON[T any](parent *bolt.Bucket, name []byte, add func(key []byte, value T)) error {
+	bucket := parent.Bucket(name)
+	if bucket == nil {
+		return fmt.Errorf("missing bucket %q", name)
+	}
+
+	return bucket.ForEach(func(key, encoded []byte) error {
+		var value T
+		if err := json.Unmarshal(encoded, &value); err != nil {
+			return fmt.Errorf("invalid value of %q in bucket %q: %w", key, name, err)
+		}
+		add(key, value)
+		return nil
+	})
+}
+
+// readSummary reads the summary of a run bucket.
+func readSummary(bucket *bolt.Bucket) (*runSummary.Summary, error) {
+	var summary runSummary.Summary
+	if err := json.Unmarshal(bucket.Get(summaryKey), &summary); err != nil {
+		return nil, fmt.Errorf("invalid summary: %w", err)
+	}
+	return &summary, nil
+}
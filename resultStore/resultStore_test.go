@@ -0,0 +1,206 @@
+package resultStore
+
+import (
+	"errors"
+	"firefly-assignment/network"
+	"firefly-assignment/runSummary"
+	"firefly-assignment/utils"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestRun(command string) *Run {
+	summary := runSummary.New(command, map[string]interface{}{"top_results": 10})
+	summary.AddArticle(runSummary.Article{URL: "https://example.com/a", Language: "en", Words: 4, CountedWords: 3})
+	summary.AddError("https://example.com/missing", network.ErrNotFound)
+	summary.Totals = runSummary.Totals{Total: 2, Processed: 1, Errored: 1}
+	summary.Finish()
+
+	run := NewRun(summary)
+	run.AddArticle("https://example.com/a", utils.WordFrequencyMap{"apple": 2, "pie": 1}, utils.WordFrequencyMap{"Apple Inc": 1})
+	run.AddError("https://example.com/missing", network.ErrNotFound)
+	run.Words = utils.WordFrequencyMap{"apple": 2, "pie": 1}
+	run.Entities = utils.WordFrequencyMap{"Apple Inc": 1}
+	return run
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name       string
+		command    string
+		expectedID uint64
+	}{
+		{name: "First run", command: "firefly run", expectedID: 1},
+		{name: "Second run", command: "firefly analyze", expectedID: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := newTestRun(tt.command)
+			id, err := store.Save(run)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tt.expectedID || run.ID != tt.expectedID {
+				t.Fatalf("expected ID %v, got %v (run ID %v)", tt.expectedID, id, run.ID)
+			}
+
+			loaded, err := store.Load(id)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if loaded.Summary.Command != tt.command || loaded.Summary.Totals != run.Summary.Totals {
+				t.Errorf("expected summary %v %+v, got %v %+v", tt.command, run.Summary.Totals, loaded.Summary.Command, loaded.Summary.Totals)
+			}
+			if len(loaded.Summary.Articles) != 1 || loaded.Summary.Errors[runSummary.ClassNotFound].Count != 1 {
+				t.Errorf("expected the articles and errors of the summary, got %+v", loaded.Summary)
+			}
+			for _, field := range []struct {
+				name             string
+				expected, actual interface{}
+			}{
+				{name: "words", expected: run.Words, actual: loaded.Words},
+				{name: "entities", expected: run.Entities, actual: loaded.Entities},
+				{name: "articles", expected: run.Articles, actual: loaded.Articles},
+				{name: "errors", expected: run.Errors, actual: loaded.Errors},
+			} {
+				if !reflect.DeepEqual(field.expected, field.actual) {
+					t.Errorf("expected %v %+v, got %+v", field.name, field.expected, field.actual)
+				}
+			}
+		})
+	}
+
+	runs, err := store.Runs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != 1 || runs[1].Summary.Command != "firefly analyze" {
+		t.Errorf("expected the two runs in order, got %+v", runs)
+	}
+}
+
+func TestLoadMissingRun(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Load(42); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Save(newTestRun("firefly run"))
+	store.Close()
+
+	// The run is still there after reopening the database.
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	run, err := store.Load(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if run.Words["apple"] != 2 {
+		t.Errorf("expected the counts of the run, got %v", run.Words)
+	}
+}
+
+func TestSaveKeepsEveryEntry(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	// The same URL is listed twice, and fails before it succeeds.
+	run := newTestRun("firefly run")
+	run.AddArticle("https://example.com/a", utils.WordFrequencyMap{"apple": 1}, nil)
+	run.AddError("https://example.com/b", network.ErrNotFound)
+	run.AddArticle("https://example.com/b", utils.WordFrequencyMap{"pie": 1}, nil)
+	id, err := store.Save(run)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := store.Load(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var articleURLs, errorURLs []string
+	for _, article := range loaded.Articles {
+		articleURLs = append(articleURLs, article.URL)
+	}
+	for _, urlError := range loaded.Errors {
+		errorURLs = append(errorURLs, urlError.URL)
+	}
+	expectedArticles := []string{"https://example.com/a", "https://example.com/a", "https://example.com/b"}
+	expectedErrors := []string{"https://example.com/missing", "https://example.com/b"}
+	if !reflect.DeepEqual(articleURLs, expectedArticles) || !reflect.DeepEqual(errorURLs, expectedErrors) {
+		t.Errorf("expected articles %v and errors %v in order, got %v and %v", expectedArticles, expectedErrors, articleURLs, errorURLs)
+	}
+}
+
+func TestLoadURLKeyedRun(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	id, err := store.Save(newTestRun("firefly run"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Rewrite the entries as older versions stored them: keyed by URL, without the URL in the value.
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(runsBucket).Bucket(runKey(id))
+		for name, value := range map[string]string{"articles": `{"counts": {"apple": 2}}`, "errors": `{"class": "not_found", "error": "not found"}`} {
+			if err := bucket.DeleteBucket([]byte(name)); err != nil {
+				return err
+			}
+			entries, err := bucket.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			if err := entries.Put([]byte("https://example.com/"+name), []byte(value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := store.Load(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedArticles := []ArticleCounts{{URL: "https://example.com/articles", Counts: utils.WordFrequencyMap{"apple": 2}}}
+	expectedErrors := []URLError{{URL: "https://example.com/errors", Class: "not_found", Error: "not found"}}
+	if !reflect.DeepEqual(loaded.Articles, expectedArticles) || !reflect.DeepEqual(loaded.Errors, expectedErrors) {
+		t.Errorf("expected %+v and %+v, got %+v and %+v", expectedArticles, expectedErrors, loaded.Articles, loaded.Errors)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Package runSummary provides a machine-readable summary of a job: the configuration it ran with,
+its timings, totals, error counts by class and top results. The summary is serialized to JSON, so that
+downstream tooling can consume the results of a run without scraping its output.
+*/
+package runSummary
+
+import (
+	"encoding/json"
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/network"
+	"firefly-assignment/utils"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Totals holds the number of entries of a run by outcome.
+type Totals struct {
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Errored   int `json:"errored"`
+}
+
+// Error classes of the run summary.
+const (
+	ClassBlocked          = "blocked"
+	ClassNotFound         = "not_found"
+	ClassTimeout          = "timeout"
+	ClassTooManyRedirects = "too_many_redirects"
+	ClassHTTPStatus       = "http_status"
+	ClassNetwork          = "network"
+	ClassNoContent        = "no_content"
+	ClassOther            = "other"
+)
+
+// maxErrorExamples is the maximum number of example URLs kept for each error class.
+const maxErrorExamples = 3
+
+// ErrorCount holds the number of errors of a class, and a few of the URLs that failed with it.
+type ErrorCount struct {
+	Count       int      `json:"count"`
+	ExampleURLs []string `json:"example_urls"`
+}
+
+// Add returns the sum of two error counts of the same class, with the example URLs of both, up to the maximum.
+//
+// Parameters:
+//   - other: The error count to add.
+//
+// Returns:
+//   - ErrorCount: The sum of the error counts.
+func (e ErrorCount) Add(other ErrorCount) ErrorCount {
+	sum := ErrorCount{Count: e.Count + other.Count}
+	sum.ExampleURLs = append(sum.ExampleURLs, e.ExampleURLs...)
+	sum.ExampleURLs = append(sum.ExampleURLs, other.ExampleURLs...)
+	if len(sum.ExampleURLs) > maxErrorExamples {
+		sum.ExampleURLs = sum.ExampleURLs[:maxErrorExamples]
+	}
+	return sum
+}
+
+// Article holds the results of a single article of a run.
+type Article struct {
+	URL          string           `json:"url"`
+	Language     string           `json:"language"`
+	Words        int              `json:"words"`
+	CountedWords int              `json:"counted_words"`
+	TopWords     []utils.WordFreq `json:"top_words"`
+}
+
+// Summary is the structured result of a run.
+type Summary struct {
+	Command         string                 `json:"command"`
+	Config          map[string]interface{} `json:"config"`
+	StartedAt       time.Time              `json:"started_at"`
+	FinishedAt      time.Time              `json:"finished_at"`
+	DurationSeconds float64                `json:"duration_seconds"`
+	Totals          Totals                 `json:"totals"`
+	Errors          map[string]ErrorCount  `json:"errors"`
+	Languages       map[string]int32       `json:"languages,omitempty"`
+	TopWords        []utils.WordFreq       `json:"top_words,omitempty"`
+	TopEntities     []utils.WordFreq       `json:"top_entities,omitempty"`
+	TopTFIDF        []utils.WordScore      `json:"top_tfidf,omitempty"`
+	Articles        []Article              `json:"articles,omitempty"`
+
+	mutex sync.Mutex
+}
+
+// New creates the summary of a run that starts now.
+//
+// Parameters:
+//   - command: The name of the command that is run.
+//   - config: A snapshot of the configuration settings of the run, keyed by configuration key.
+//
+// Returns:
+//   - *Summary: The summary, to be completed while the run progresses.
+func New(command string, config map[string]interface{}) *Summary {
+	return &Summary{
+		Command:   command,
+		Config:    config,
+		StartedAt: time.Now(),
+		Errors:    make(map[string]ErrorCount),
+	}
+}
+
+// AddError counts an error under its class (see ErrorClass), and keeps the URL as an example of the class.
+// It is safe to call AddError concurrently.
+//
+// Parameters:
+//   - url: The URL that failed.
+//   - err: The error of the URL.
+func (s *Summary) AddError(url string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	class := ErrorClass(err)
+	errorCount := s.Errors[class]
+	errorCount.Count++
+	if len(errorCount.ExampleURLs) < maxErrorExamples {
+		errorCount.ExampleURLs = append(errorCount.ExampleURLs, url)
+	}
+	s.Errors[class] = errorCount
+}
+
+// AddArticle adds the results of an article to the summary. It is safe to call AddArticle concurrently.
+//
+// Parameters:
+//   - article: The results of the article.
+func (s *Summary) AddArticle(article Article) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Articles = append(s.Articles, article)
+}
+
+// Copy returns a copy of the summary, with its own errors, languages, top results and articles, so that the copy
+// can be completed and written while the run goes on. It is safe to call Copy concurrently.
+//
+// Returns:
+//   - *Summary: The copy of the summary.
+func (s *Summary) Copy() *Summary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return &Summary{
+		Command:         s.Command,
+		Config:          s.Config,
+		StartedAt:       s.StartedAt,
+		FinishedAt:      s.FinishedAt,
+		DurationSeconds: s.DurationSeconds,
+		Totals:          s.Totals,
+		Errors:          maps.Clone(s.Errors),
+		Languages:       maps.Clone(s.Languages),
+		TopWords:        slices.Clone(s.TopWords),
+		TopEntities:     slices.Clone(s.TopEntities),
+		TopTFIDF:        slices.Clone(s.TopTFIDF),
+		Articles:        slices.Clone(s.Articles),
+	}
+}
+
+// Finish records the end of the run and computes its duration.
+// The articles are sorted by URL, so that the summary does not depend on the order in which they were processed.
+func (s *Summary) Finish() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sort.Slice(s.Articles, func(i, j int) bool { return s.Articles[i].URL < s.Articles[j].URL })
+	s.FinishedAt = time.Now()
+	s.DurationSeconds = s.FinishedAt.Sub(s.StartedAt).Seconds()
+}
+
+// Write writes the summary to the writer as pretty-formatted JSON.
+//
+// Parameters:
+//   - w: The writer to write the summary to.
+//
+// Returns:
+//   - error: An error if the summary cannot be converted to JSON or written.
+func (s *Summary) Write(w io.Writer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("could not convert summary to JSON: %w", err)
+	}
+	return nil
+}
+
+// Save writes the summary as JSON to the file at the given path, replacing the file if it exists.
+//
+// Parameters:
+//   - path: The path of the file.
+//
+// Returns:
+//   - error: An error if the file cannot be written.
+func (s *Summary) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create summary file: %w", err)
+	}
+	defer file.Close()
+
+	if err := s.Write(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ErrorClass classifies an error of a URL, so that the errors of a run can be broken down by cause.
+// The most specific class wins: for example, a page that was still not found after all retries
+// is classified as ClassNotFound rather than ClassNetwork.
+//
+// Parameters:
+//   - err: The error of a URL.
+//
+// Returns:
+//   - string: The class of the error, one of the Class* constants.
+func ErrorClass(err error) string {
+	var statusErr *network.StatusError
+	switch {
+	case errors.Is(err, network.ErrBlocked):
+		return ClassBlocked
+	case errors.Is(err, network.ErrNotFound):
+		return ClassNotFound
+	case errors.Is(err, network.ErrTimeout):
+		return ClassTimeout
+	case errors.Is(err, network.ErrTooManyRedirects):
+		return ClassTooManyRedirects
+	case errors.As(err, &statusErr), errors.Is(err, network.ErrNoLocation):
+		return ClassHTTPStatus
+	case errors.Is(err, network.ErrTooManyRetries):
+		return ClassNetwork
+	case errors.Is(err, article.ErrNoContent):
+		return ClassNoContent
+	default:
+		return ClassOther
+	}
+}
@@ -0,0 +1,173 @@
+package runSummary
+
+import (
+	"encoding/json"
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/network"
+	"firefly-assignment/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "Blocked", err: &network.StatusError{StatusCode: network.StatusBlocked}, expected: ClassBlocked},
+		{
+			name:     "Not found after retries",
+			err:      fmt.Errorf("%w: %w", network.ErrTooManyRetries, &network.StatusError{StatusCode: 404}),
+			expected: ClassNotFound,
+		},
+		{
+			name:     "Timeout after retries",
+			err:      fmt.Errorf("%w: %w: %w", network.ErrTooManyRetries, network.ErrTimeout, errors.New("i/o timeout")),
+			expected: ClassTimeout,
+		},
+		{name: "Too many redirects", err: network.ErrTooManyRedirects, expected: ClassTooManyRedirects},
+		{name: "Unexpected status", err: &network.StatusError{StatusCode: 500}, expected: ClassHTTPStatus},
+		{name: "Redirect without location", err: network.ErrNoLocation, expected: ClassHTTPStatus},
+		{
+			name:     "Network error after retries",
+			err:      fmt.Errorf("%w: %w", network.ErrTooManyRetries, errors.New("connection refused")),
+			expected: ClassNetwork,
+		},
+		{
+			name:     "No content",
+			err:      fmt.Errorf("failed to extract article content: %w", article.ErrNoContent),
+			expected: ClassNoContent,
+		},
+		{name: "Other error", err: errors.New("disk full"), expected: ClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if class := ErrorClass(tt.err); class != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, class)
+			}
+		})
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	summary := New("run", map[string]interface{}{"top_results": 2})
+	for i := 1; i <= 4; i++ {
+		summary.AddError(fmt.Sprintf("https://example.com/missing-%d", i), &network.StatusError{StatusCode: 404})
+	}
+	summary.AddError("https://example.com/forbidden", &network.StatusError{StatusCode: 403})
+	summary.Totals = Totals{Total: 7, Processed: 2, Errored: 5}
+	summary.TopWords = []utils.WordFreq{{Word: "test", Frequency: 3}, {Word: "example", Frequency: 1}}
+	summary.AddArticle(Article{URL: "https://example.com/b", Language: "en", Words: 3, CountedWords: 1})
+	summary.AddArticle(Article{URL: "https://example.com/a", Language: "en", Words: 5, CountedWords: 3})
+	summary.Finish()
+
+	if summary.Articles[0].URL != "https://example.com/a" {
+		t.Errorf("expected articles sorted by URL, got %v", summary.Articles)
+	}
+
+	if summary.DurationSeconds < 0 || summary.FinishedAt.Before(summary.StartedAt) {
+		t.Errorf("expected finish after start, got %v -> %v", summary.StartedAt, summary.FinishedAt)
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := summary.Save(path); err != nil {
+		t.Fatalf("unexpected error saving summary: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading summary: %v", err)
+	}
+
+	var decoded struct {
+		Command  string                `json:"command"`
+		Config   map[string]int        `json:"config"`
+		Totals   Totals                `json:"totals"`
+		Errors   map[string]ErrorCount `json:"errors"`
+		TopWords []utils.WordFreq      `json:"top_words"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+
+	if decoded.Command != "run" || decoded.Config["top_results"] != 2 {
+		t.Errorf("unexpected command or config: %+v", decoded)
+	}
+	if decoded.Totals != summary.Totals {
+		t.Errorf("expected totals %+v, got %+v", summary.Totals, decoded.Totals)
+	}
+	expectedErrors := map[string]ErrorCount{
+		ClassNotFound:   {Count: 4, ExampleURLs: []string{"https://example.com/missing-1", "https://example.com/missing-2", "https://example.com/missing-3"}},
+		ClassHTTPStatus: {Count: 1, ExampleURLs: []string{"https://example.com/forbidden"}},
+	}
+	if !reflect.DeepEqual(decoded.Errors, expectedErrors) {
+		t.Errorf("expected errors %v, got %v", expectedErrors, decoded.Errors)
+	}
+	if !reflect.DeepEqual(decoded.TopWords, summary.TopWords) {
+		t.Errorf("expected top words %v, got %v", summary.TopWords, decoded.TopWords)
+	}
+	if strings.Contains(string(data), "top_entities") {
+		t.Error("expected empty top entities to be omitted")
+	}
+}
+
+func TestErrorCountAdd(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        ErrorCount
+		b        ErrorCount
+		expected ErrorCount
+	}{
+		{
+			name:     "Add to an empty count",
+			b:        ErrorCount{Count: 1, ExampleURLs: []string{"a"}},
+			expected: ErrorCount{Count: 1, ExampleURLs: []string{"a"}},
+		},
+		{
+			name:     "Keep the examples of both",
+			a:        ErrorCount{Count: 1, ExampleURLs: []string{"a"}},
+			b:        ErrorCount{Count: 1, ExampleURLs: []string{"b"}},
+			expected: ErrorCount{Count: 2, ExampleURLs: []string{"a", "b"}},
+		},
+		{
+			name:     "Limit the examples",
+			a:        ErrorCount{Count: 5, ExampleURLs: []string{"a", "b", "c"}},
+			b:        ErrorCount{Count: 2, ExampleURLs: []string{"d", "e"}},
+			expected: ErrorCount{Count: 7, ExampleURLs: []string{"a", "b", "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sum := tt.a.Add(tt.b); !reflect.DeepEqual(sum, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, sum)
+			}
+		})
+	}
+}
+
+func TestSummaryCopy(t *testing.T) {
+	summary := New("firefly run", nil)
+	summary.AddArticle(Article{URL: "https://example.com/a", Language: "en", Words: 4, CountedWords: 3})
+	summary.AddError("https://example.com/missing", &network.StatusError{StatusCode: 404})
+	summary.Languages = map[string]int32{"en": 1}
+
+	copied := summary.Copy()
+	copied.TopWords = []utils.WordFreq{{Word: "apple", Frequency: 2}}
+	copied.Languages["de"] = 1
+	summary.AddArticle(Article{URL: "https://example.com/b", Language: "en", Words: 2, CountedWords: 1})
+	summary.AddError("https://example.com/forbidden", &network.StatusError{StatusCode: 403})
+
+	if len(copied.Articles) != 1 || len(copied.Errors) != 1 || copied.Command != "firefly run" {
+		t.Errorf("expected the copy to keep the articles and errors at the time of the copy, got %+v", copied)
+	}
+	if summary.TopWords != nil || len(summary.Languages) != 1 {
+		t.Errorf("expected the summary to be left as is, got %+v", summary)
+	}
+}
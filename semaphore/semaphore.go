@@ -0,0 +1,75 @@
+// Package semaphore implements a counting semaphore whose limit can be changed while it is in use,
+// to adjust the number of concurrent requests of a running job.
+package semaphore
+
+import "sync"
+
+// Semaphore limits the number of concurrent holders to a limit that can be changed at any time.
+type Semaphore struct {
+	mutex sync.Mutex
+	cond  *sync.Cond
+	limit int
+	inUse int
+}
+
+// New creates a semaphore that allows up to 'limit' concurrent holders.
+//
+// Parameters:
+//   - limit: The maximum number of concurrent holders.
+//
+// Returns:
+//   - *Semaphore: The semaphore.
+func New(limit int) *Semaphore {
+	s := &Semaphore{limit: limit}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
+}
+
+// Acquire blocks until the number of holders is below the limit, and then takes a slot.
+func (s *Semaphore) Acquire() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.inUse >= s.limit {
+		s.cond.Wait()
+	}
+	s.inUse++
+}
+
+// Release frees a slot taken with Acquire.
+func (s *Semaphore) Release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.inUse--
+	s.cond.Signal()
+}
+
+// SetLimit changes the maximum number of concurrent holders. Lowering the limit does not interrupt
+// current holders; new holders wait until the number of holders drops below the new limit.
+//
+// Parameters:
+//   - limit: The new maximum number of concurrent holders.
+func (s *Semaphore) SetLimit(limit int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.limit = limit
+	s.cond.Broadcast()
+}
+
+// Limit returns the maximum number of concurrent holders.
+func (s *Semaphore) Limit() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.limit
+}
+
+// InUse returns the current number of holders.
+func (s *Semaphore) InUse() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.inUse
+}
@@ -0,0 +1,85 @@
+package semaphore
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphoreLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		workers     int
+		expectedMax int32
+	}{
+		{name: "Limit of one", limit: 1, workers: 5, expectedMax: 1},
+		{name: "Limit of three", limit: 3, workers: 10, expectedMax: 3},
+		{name: "Limit above workers", limit: 10, workers: 4, expectedMax: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.limit)
+			var current, max int32
+			var wg sync.WaitGroup
+
+			for i := 0; i < tt.workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.Acquire()
+					defer s.Release()
+
+					n := atomic.AddInt32(&current, 1)
+					for {
+						old := atomic.LoadInt32(&max)
+						if n <= old || atomic.CompareAndSwapInt32(&max, old, n) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&current, -1)
+				}()
+			}
+			wg.Wait()
+
+			if max > tt.expectedMax {
+				t.Errorf("expected at most %d concurrent holders, got %d", tt.expectedMax, max)
+			}
+			if s.InUse() != 0 {
+				t.Errorf("expected no holders after all workers finished, got %d", s.InUse())
+			}
+		})
+	}
+}
+
+func TestSemaphoreSetLimit(t *testing.T) {
+	s := New(1)
+	s.Acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		s.Acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected second Acquire to block while the limit is reached")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Raising the limit must wake up the waiting holder.
+	s.SetLimit(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected second Acquire to succeed after raising the limit")
+	}
+
+	if s.Limit() != 2 || s.InUse() != 2 {
+		t.Errorf("expected limit 2 and 2 holders, got limit %d and %d holders", s.Limit(), s.InUse())
+	}
+}
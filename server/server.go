@@ -0,0 +1,253 @@
+/*
+Package server provides an HTTP API to the analyzer, so that other services can call it. Jobs of URLs to
+fetch, or of documents submitted inline as HTML or plain text, are submitted to a job manager that runs
+them in the background (see the jobManager package); their status is polled, they can be cancelled, and
+their results are fetched in any output format. A single text can also be analyzed synchronously.
+
+Endpoints:
+  - POST /jobs: Submits a job (a jobManager.Spec), and returns its status with 202 Accepted.
+  - GET /jobs: Returns the status of the queued, running and finished jobs.
+  - GET /jobs/{id}: Returns the status of a job.
+  - DELETE /jobs/{id}: Cancels a queued or running job, and returns its status.
+  - GET /jobs/{id}/results: Returns the results of a finished job, in the format of the `format` query parameter.
+  - POST /analyze: Analyzes the request body (HTML if its content type is text/html, plain text otherwise),
+    and returns its results in the format of the `format` query parameter.
+
+The `top` query parameter sets the number of top results.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"firefly-assignment/article"
+	"firefly-assignment/display"
+	"firefly-assignment/jobManager"
+	"firefly-assignment/runSummary"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// Defaults of the Server.
+const (
+	DefaultOutputFormat = "json"
+	DefaultMaxBodySize  = 10 << 20
+)
+
+// contentTypes maps each output format to the content type of its responses.
+var contentTypes = map[string]string{
+	"json":     "application/json",
+	"ndjson":   "application/x-ndjson",
+	"csv":      "text/csv; charset=utf-8",
+	"tsv":      "text/tab-separated-values; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+	"table":    "text/plain; charset=utf-8",
+	"html":     "text/html; charset=utf-8",
+}
+
+// Server serves the HTTP API, and hands the submitted jobs over to its job manager.
+type Server struct {
+	manager      *jobManager.Manager
+	outputFormat string
+	maxBodySize  int64
+}
+
+// Option configures a Server.
+type Option func(s *Server)
+
+// WithOutputFormat sets the output format of the results when the request does not set `format`.
+func WithOutputFormat(outputFormat string) Option {
+	return func(s *Server) { s.outputFormat = outputFormat }
+}
+
+// WithMaxBodySize sets the maximum size of a request body, in bytes.
+func WithMaxBodySize(maxBodySize int64) Option {
+	return func(s *Server) { s.maxBodySize = maxBodySize }
+}
+
+// New creates a Server with the default settings, overridden by the given options.
+//
+// Parameters:
+//   - manager: The job manager that runs the submitted jobs.
+//   - opts: The options to apply to the Server.
+//
+// Returns:
+//   - *Server: The configured Server.
+func New(manager *jobManager.Manager, opts ...Option) *Server {
+	s := &Server{
+		manager:      manager,
+		outputFormat: DefaultOutputFormat,
+		maxBodySize:  DefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Handler returns the HTTP handler of the API.
+//
+// Returns:
+//   - http.Handler: The handler that routes the requests to the endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.submitJob)
+	mux.HandleFunc("GET /jobs", s.listJobs)
+	mux.HandleFunc("GET /jobs/{id}", s.jobStatus)
+	mux.HandleFunc("DELETE /jobs/{id}", s.cancelJob)
+	mux.HandleFunc("GET /jobs/{id}/results", s.jobResults)
+	mux.HandleFunc("POST /analyze", s.analyze)
+	return mux
+}
+
+// submitJob submits a job with the URLs, documents and settings of the request body, and returns its status.
+func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
+	var spec jobManager.Spec
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		writeError(w, readErrorStatus(err), fmt.Errorf("invalid job request: %w", err))
+		return
+	}
+
+	job, err := s.manager.Submit(spec)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID())
+	writeJSON(w, http.StatusAccepted, job.Status())
+}
+
+// listJobs returns the status of the jobs of the manager.
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.manager.Jobs())
+}
+
+// jobStatus returns the status of the job of the path.
+func (s *Server) jobStatus(w http.ResponseWriter, r *http.Request) {
+	job, found := s.manager.Job(r.PathValue("id"))
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %q not found", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Status())
+}
+
+// cancelJob cancels the job of the path, and returns its status.
+func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.manager.Cancel(id); err != nil {
+		statusCode := http.StatusConflict
+		if errors.Is(err, jobManager.ErrJobNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		writeError(w, statusCode, fmt.Errorf("could not cancel job %q: %w", id, err))
+		return
+	}
+
+	job, found := s.manager.Job(id)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %q not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Status())
+}
+
+// jobResults returns the results of the finished job of the path, in the requested format.
+func (s *Server) jobResults(w http.ResponseWriter, r *http.Request) {
+	job, found := s.manager.Job(r.PathValue("id"))
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %q not found", r.PathValue("id")))
+		return
+	}
+	if !job.Finished() {
+		status := job.Status()
+		writeError(w, http.StatusConflict, fmt.Errorf("job %q is %v", status.ID, status.Status))
+		return
+	}
+	s.writeResults(w, r, job)
+}
+
+// analyze analyzes the request body synchronously, and returns its results in the requested format.
+func (s *Server) analyze(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	if err != nil {
+		writeError(w, readErrorStatus(err), fmt.Errorf("could not read the document: %w", err))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var content article.Article
+	if mediaType == "text/html" {
+		if content, err = s.manager.Extractor().GetArticle(string(body)); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+	} else {
+		content = article.FromText(string(body))
+	}
+
+	s.writeResults(w, r, s.manager.Analyze(content))
+}
+
+// writeResults writes the top results of a finished job in the format and number set by the `format`
+// and `top` query parameters. Report formats render the whole summary of the job.
+func (s *Server) writeResults(w http.ResponseWriter, r *http.Request, job *jobManager.Job) {
+	format := s.outputFormat
+	if value := r.URL.Query().Get("format"); value != "" {
+		format = value
+	}
+	formatter, err := display.NewFormatter(format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	n := job.TopResults()
+	if value := r.URL.Query().Get("top"); value != "" {
+		if n, err = strconv.Atoi(value); err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid top %q, expected a positive number", value))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	err = job.WriteResults(n, func(summary *runSummary.Summary) error {
+		if reportFormatter, ok := formatter.(display.ReportFormatter); ok {
+			return reportFormatter.FormatReport(w, summary)
+		}
+		return formatter.Format(w, summary.TopWords)
+	})
+	if err != nil {
+		slog.Error("Could not write the results", "job", job.ID(), "error", err)
+	}
+}
+
+// writeJSON writes the value as the JSON body of a response with the given status code.
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", contentTypes["json"])
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Could not write the response", "error", err)
+	}
+}
+
+// readErrorStatus returns the status code of an error reading a request body: 413 if the body is larger
+// than the maximum body size, and 400 otherwise, such as for invalid JSON or a client that disconnected.
+func readErrorStatus(err error) int {
+	if errors.As(err, new(*http.MaxBytesError)) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// writeError writes the error as the JSON body of a response with the given status code.
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"encoding/json"
+	"firefly-assignment/article"
+	"firefly-assignment/jobManager"
+	"firefly-assignment/network"
+	"firefly-assignment/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// pagesClient serves the pages of a map keyed by URL, and 404 for any other URL.
+type pagesClient map[string]string
+
+func (c pagesClient) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	body, exists := c[string(req.RequestURI())]
+	if !exists {
+		resp.SetStatusCode(http.StatusNotFound)
+		return nil
+	}
+	resp.SetStatusCode(http.StatusOK)
+	resp.SetBodyString(body)
+	return nil
+}
+
+func newTestServer(t *testing.T) (*jobManager.Manager, *httptest.Server) {
+	fetcher := network.NewFetcher(network.WithHTTPClient(pagesClient{
+		"https://example.com/a": `<html><body><div class="caas-body">The apple phone and the apple watch.</div></body></html>`,
+		"https://example.com/b": `<html><body><div class="caas-body">A phone.</div></body></html>`,
+		"https://example.com/c": `<html><body><p>No article here.</p></body></html>`,
+	}), network.WithMaxRetries(0))
+	wordBanks := utils.LanguageWordBanks{article.DefaultLanguage: {"apple": {}, "phone": {}, "watch": {}}}
+
+	manager := jobManager.New(fetcher, article.NewExtractor(), wordBanks, jobManager.WithTopResults(2))
+	httpServer := httptest.NewServer(New(manager).Handler())
+	t.Cleanup(httpServer.Close)
+	return manager, httpServer
+}
+
+func request(t *testing.T, method string, url string, contentType string, body string) (*http.Response, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func TestJobLifecycle(t *testing.T) {
+	manager, httpServer := newTestServer(t)
+
+	resp, body := request(t, http.MethodPost, httpServer.URL+"/jobs", "application/json",
+		`{"urls": ["https://example.com/a", "https://example.com/b", "https://example.com/missing"], "html": ["<div class=\"caas-body\">Apple</div>"], "text": ["phone phone"]}`)
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "/jobs/1" {
+		t.Fatalf("expected 202 with the job location, got %v %v: %v", resp.StatusCode, resp.Header.Get("Location"), body)
+	}
+	manager.Wait()
+
+	resp, body = request(t, http.MethodGet, httpServer.URL+"/jobs/1", "", "")
+	var status jobManager.JobStatus
+	if err := json.Unmarshal([]byte(body), &status); err != nil {
+		t.Fatalf("invalid status %q: %v", body, err)
+	}
+	if resp.StatusCode != http.StatusOK || status.Status != jobManager.StatusDone || status.Total != 5 || status.Processed != 4 || status.Errored != 1 || status.FinishedAt == nil {
+		t.Errorf("unexpected status %v: %+v", resp.StatusCode, status)
+	}
+
+	resp, body = request(t, http.MethodGet, httpServer.URL+"/jobs", "", "")
+	var statuses []jobManager.JobStatus
+	if err := json.Unmarshal([]byte(body), &statuses); err != nil {
+		t.Fatalf("invalid job list %q: %v", body, err)
+	}
+	if resp.StatusCode != http.StatusOK || len(statuses) != 1 || statuses[0].ID != "1" {
+		t.Errorf("unexpected job list %v: %+v", resp.StatusCode, statuses)
+	}
+
+	resp, body = request(t, http.MethodDelete, httpServer.URL+"/jobs/1", "", "")
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %v when cancelling a finished job, got %v: %v", http.StatusConflict, resp.StatusCode, body)
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Default format and top results",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        "[\n    {\n        \"Word\": \"phone\",\n        \"Frequency\": 4\n    },\n    {\n        \"Word\": \"apple\",\n        \"Frequency\": 3\n    }\n]\n",
+		},
+		{
+			name:                "CSV with more results",
+			query:               "?format=csv&top=3",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "word,frequency\nphone,4\napple,3\nwatch,1\n",
+		},
+		{name: "Unsupported format", query: "?format=xml", expectedStatus: http.StatusBadRequest},
+		{name: "Invalid top", query: "?top=none", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := request(t, http.MethodGet, httpServer.URL+"/jobs/1/results"+tt.query, "", "")
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %v, got %v: %v", tt.expectedStatus, resp.StatusCode, body)
+			}
+			if tt.expectedBody == "" {
+				return
+			}
+			if resp.Header.Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %q, got %q", tt.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			if body != tt.expectedBody {
+				t.Errorf("expected body:\n%v\ngot:\n%v", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestJobErrors(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Invalid JSON", method: http.MethodPost, path: "/jobs", body: `{"urls": `, expectedStatus: http.StatusBadRequest},
+		{name: "Unknown field", method: http.MethodPost, path: "/jobs", body: `{"pages": ["a"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "No documents", method: http.MethodPost, path: "/jobs", body: `{"urls": []}`, expectedStatus: http.StatusBadRequest},
+		{name: "Negative setting", method: http.MethodPost, path: "/jobs", body: `{"urls": ["a"], "top_results": -1}`, expectedStatus: http.StatusBadRequest},
+		{name: "Unknown job status", method: http.MethodGet, path: "/jobs/42", expectedStatus: http.StatusNotFound},
+		{name: "Unknown job results", method: http.MethodGet, path: "/jobs/42/results", expectedStatus: http.StatusNotFound},
+		{name: "Unknown job cancellation", method: http.MethodDelete, path: "/jobs/42", expectedStatus: http.StatusNotFound},
+		{name: "Unsupported method", method: http.MethodPut, path: "/jobs/42", expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := request(t, tt.method, httpServer.URL+tt.path, "application/json", tt.body)
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %v, got %v: %v", tt.expectedStatus, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		query          string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Plain text",
+			contentType:    "text/plain",
+			query:          "?format=csv",
+			body:           "An apple phone, an apple.",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "word,frequency\napple,2\nphone,1\n",
+		},
+		{
+			name:           "HTML",
+			contentType:    "text/html; charset=utf-8",
+			query:          "?format=csv&top=1",
+			body:           `<html><body><div class="caas-body">Watch the watch.</div></body></html>`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "word,frequency\nwatch,2\n",
+		},
+		{
+			name:           "HTML without article",
+			contentType:    "text/html",
+			body:           `<html><body><p>Watch.</p></body></html>`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := request(t, http.MethodPost, httpServer.URL+"/analyze"+tt.query, tt.contentType, tt.body)
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %v, got %v: %v", tt.expectedStatus, resp.StatusCode, body)
+			}
+			if tt.expectedBody != "" && body != tt.expectedBody {
+				t.Errorf("expected body:\n%v\ngot:\n%v", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+// failingReader fails like the body of a request whose client disconnected.
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestRequestBodyErrors(t *testing.T) {
+	manager := jobManager.New(network.NewFetcher(), article.NewExtractor(), utils.LanguageWordBanks{})
+	handler := New(manager, WithMaxBodySize(16)).Handler()
+
+	tests := []struct {
+		name           string
+		path           string
+		body           io.Reader
+		expectedStatus int
+	}{
+		{name: "Document too large", path: "/analyze", body: strings.NewReader("a document that is too long"), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Document read failure", path: "/analyze", body: failingReader{}, expectedStatus: http.StatusBadRequest},
+		{name: "Job request too large", path: "/jobs", body: strings.NewReader(`{"urls": ["https://example.com/a"]}`), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Job request read failure", path: "/jobs", body: failingReader{}, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.path, tt.body))
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("expected status %v, got %v: %v", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
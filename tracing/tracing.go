@@ -0,0 +1,305 @@
+/*
+Package tracing records where the time goes for each URL of a job, as spans in the style of OpenTelemetry:
+each URL is a trace whose root span covers its whole processing, with a child span for each of its stages,
+such as the wait for the rate limiter, the requests and the parsing. The spans are written to a local file
+as JSON lines, without any collector, and the slowest stages over all URLs are summarized at the end.
+*/
+package tracing
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Statuses of a span.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Span is a timed operation of a trace.
+type Span struct {
+	TraceID      string            `json:"trace_id"`
+	SpanID       string            `json:"span_id"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	Name         string            `json:"name"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	DurationMs   float64           `json:"duration_ms"`
+	Status       string            `json:"status"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// Duration returns the duration of the span.
+func (s Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// StageSummary sums up the spans of a stage over all traces.
+type StageSummary struct {
+	Name         string
+	Count        int
+	Total        time.Duration
+	Mean         time.Duration
+	P95          time.Duration
+	Max          time.Duration
+	SlowestTrace string // The name of the trace of the slowest span
+}
+
+// stageStats holds the durations of the spans of a stage.
+type stageStats struct {
+	durations    []time.Duration
+	max          time.Duration
+	slowestTrace string
+}
+
+// Tracer starts the traces of a job, writes their spans once they end, and keeps the statistics of their stages.
+// It is safe for concurrent use.
+type Tracer struct {
+	mutex  sync.Mutex
+	writer *bufio.Writer
+	stages map[string]*stageStats
+}
+
+// New creates a Tracer that writes the spans to the given writer, one JSON object per line.
+//
+// Parameters:
+//   - w: The writer of the spans.
+//
+// Returns:
+//   - *Tracer: The Tracer.
+func New(w io.Writer) *Tracer {
+	return &Tracer{writer: bufio.NewWriter(w), stages: make(map[string]*stageStats)}
+}
+
+// Create creates the file of the spans at the given path, and a Tracer that writes to it.
+//
+// Parameters:
+//   - path: The path of the file.
+//
+// Returns:
+//   - *Tracer: The Tracer of the file.
+//   - *os.File: The file, to be closed once the Tracer is flushed.
+//   - error: An error if the file cannot be created.
+func Create(path string) (*Tracer, *os.File, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create trace file: %w", err)
+	}
+	return New(file), file, nil
+}
+
+// Start starts a trace, whose root span starts now.
+//
+// Parameters:
+//   - name: The name of the root span, such as "url".
+//   - attributes: The attributes of the root span, such as the URL.
+//
+// Returns:
+//   - *Trace: The trace.
+func (t *Tracer) Start(name string, attributes map[string]string) *Trace {
+	traceID := newID(16)
+	return &Trace{
+		tracer: t,
+		root: Span{
+			TraceID:    traceID,
+			SpanID:     newID(8),
+			Name:       name,
+			StartTime:  time.Now(),
+			Status:     StatusOK,
+			Attributes: attributes,
+		},
+	}
+}
+
+// Flush writes the buffered spans.
+//
+// Returns:
+//   - error: An error if the spans cannot be written.
+func (t *Tracer) Flush() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.writer.Flush()
+}
+
+// Summary sums up the spans of each stage over all ended traces, slowest stages first.
+//
+// Returns:
+//   - []StageSummary: The summary of each stage, by total duration in descending order.
+func (t *Tracer) Summary() []StageSummary {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	summaries := make([]StageSummary, 0, len(t.stages))
+	for name, stats := range t.stages {
+		durations := append([]time.Duration(nil), stats.durations...)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+		summary := StageSummary{Name: name, Count: len(durations), Max: stats.max, SlowestTrace: stats.slowestTrace}
+		for _, duration := range durations {
+			summary.Total += duration
+		}
+		summary.Mean = summary.Total / time.Duration(len(durations))
+		summary.P95 = durations[(len(durations)*95+99)/100-1]
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Total != summaries[j].Total {
+			return summaries[i].Total > summaries[j].Total
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// WriteSummary writes the summary of the stages as a table.
+//
+// Parameters:
+//   - w: The writer of the table.
+//
+// Returns:
+//   - error: An error if the table cannot be written.
+func (t *Tracer) WriteSummary(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "STAGE\tCOUNT\tTOTAL\tMEAN\tP95\tMAX\tSLOWEST")
+	for _, stage := range t.Summary() {
+		fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", stage.Name, stage.Count, round(stage.Total), round(stage.Mean),
+			round(stage.P95), round(stage.Max), stage.SlowestTrace)
+	}
+	return table.Flush()
+}
+
+// end writes the spans of an ended trace, and adds its stages to the statistics.
+func (t *Tracer) end(root Span, spans []Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	encoder := json.NewEncoder(t.writer)
+	for _, span := range append([]Span{root}, spans...) {
+		if err := encoder.Encode(span); err != nil {
+			return
+		}
+	}
+
+	for _, span := range spans {
+		stats, exists := t.stages[span.Name]
+		if !exists {
+			stats = &stageStats{}
+			t.stages[span.Name] = stats
+		}
+		duration := span.Duration()
+		stats.durations = append(stats.durations, duration)
+		if duration > stats.max || len(stats.durations) == 1 {
+			stats.max = duration
+			stats.slowestTrace = traceName(root)
+		}
+	}
+}
+
+// Trace is the trace of a URL: a root span and a child span for each recorded stage. It is safe for
+// concurrent use. A nil Trace records nothing, so that the stages can be recorded whether tracing is enabled or not.
+type Trace struct {
+	tracer *Tracer
+	mutex  sync.Mutex
+	root   Span
+	spans  []Span
+}
+
+// Record records a stage of the trace, as a child span of the root span.
+//
+// Parameters:
+//   - name: The name of the stage.
+//   - start: When the stage started.
+//   - end: When the stage ended.
+func (tr *Trace) Record(name string, start time.Time, end time.Time) {
+	if tr == nil {
+		return
+	}
+
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	tr.spans = append(tr.spans, Span{
+		TraceID:      tr.root.TraceID,
+		SpanID:       newID(8),
+		ParentSpanID: tr.root.SpanID,
+		Name:         name,
+		StartTime:    start,
+		EndTime:      end,
+		DurationMs:   durationMs(end.Sub(start)),
+		Status:       StatusOK,
+	})
+}
+
+// Since records a stage of the trace that started at the given time and ends now.
+//
+// Parameters:
+//   - name: The name of the stage.
+//   - start: When the stage started.
+func (tr *Trace) Since(name string, start time.Time) {
+	tr.Record(name, start, time.Now())
+}
+
+// End ends the root span of the trace, with the error status if the URL failed, and hands the trace over to the Tracer.
+//
+// Parameters:
+//   - err: The error of the URL, or nil.
+func (tr *Trace) End(err error) {
+	if tr == nil {
+		return
+	}
+
+	tr.mutex.Lock()
+	root, spans := tr.root, tr.spans
+	tr.mutex.Unlock()
+
+	root.EndTime = time.Now()
+	root.DurationMs = durationMs(root.Duration())
+	if err != nil {
+		root.Status = StatusError
+		attributes := map[string]string{"error": err.Error()}
+		for key, value := range root.Attributes {
+			attributes[key] = value
+		}
+		root.Attributes = attributes
+	}
+	tr.tracer.end(root, spans)
+}
+
+// traceName returns the name of a trace: its URL if it has one, and the name of its root span otherwise.
+func traceName(root Span) string {
+	if url, exists := root.Attributes["url"]; exists {
+		return url
+	}
+	return root.Name
+}
+
+// newID returns a random hexadecimal ID of the given number of bytes, like the trace and span IDs of OpenTelemetry.
+func newID(bytes int) string {
+	id := make([]byte, bytes)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// durationMs returns the duration in milliseconds.
+func durationMs(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// round rounds a duration to a readable precision.
+func round(duration time.Duration) time.Duration {
+	if duration < time.Millisecond {
+		return duration.Round(time.Microsecond)
+	}
+	return duration.Round(100 * time.Microsecond)
+}
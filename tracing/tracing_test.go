@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readSpans decodes the spans written by a Tracer.
+func readSpans(t *testing.T, data []byte) []Span {
+	var spans []Span
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var span Span
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("invalid span %q: %v", scanner.Text(), err)
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func TestTrace(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatus     string
+		expectedAttributes map[string]string
+	}{
+		{
+			name:               "Processed URL",
+			expectedStatus:     StatusOK,
+			expectedAttributes: map[string]string{"url": "https://example.com/a"},
+		},
+		{
+			name:               "Failed URL",
+			err:                errors.New("page not found"),
+			expectedStatus:     StatusError,
+			expectedAttributes: map[string]string{"url": "https://example.com/a", "error": "page not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			tracer := New(&buffer)
+			trace := tracer.Start("url", map[string]string{"url": "https://example.com/a"})
+			trace.Record("limiter_wait", start, start.Add(2*time.Millisecond))
+			trace.Record("parse", start.Add(5*time.Millisecond), start.Add(6*time.Millisecond))
+			trace.End(tt.err)
+			if err := tracer.Flush(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			spans := readSpans(t, buffer.Bytes())
+			if len(spans) != 3 {
+				t.Fatalf("expected the root span and 2 stages, got %+v", spans)
+			}
+			root := spans[0]
+			if root.Name != "url" || root.Status != tt.expectedStatus || !reflect.DeepEqual(root.Attributes, tt.expectedAttributes) {
+				t.Errorf("unexpected root span %+v", root)
+			}
+			for _, span := range spans[1:] {
+				if span.TraceID != root.TraceID || span.ParentSpanID != root.SpanID || span.SpanID == root.SpanID {
+					t.Errorf("expected a child span of the root span, got %+v", span)
+				}
+			}
+			if spans[1].Name != "limiter_wait" || spans[1].DurationMs != 2 {
+				t.Errorf("expected the limiter wait of 2ms, got %+v", spans[1])
+			}
+		})
+	}
+}
+
+func TestNilTrace(t *testing.T) {
+	var trace *Trace
+	trace.Record("parse", time.Now(), time.Now())
+	trace.Since("count", time.Now())
+	trace.End(nil)
+}
+
+func TestSummary(t *testing.T) {
+	tracer := New(&bytes.Buffer{})
+	start := time.Now()
+	for i, url := range []string{"a", "b", "c", "d"} {
+		trace := tracer.Start("url", map[string]string{"url": url})
+		trace.Record("ttfb", start, start.Add(time.Duration(i+1)*10*time.Millisecond))
+		trace.Record("parse", start, start.Add(time.Millisecond))
+		trace.End(nil)
+	}
+
+	expected := []StageSummary{
+		{Name: "ttfb", Count: 4, Total: 100 * time.Millisecond, Mean: 25 * time.Millisecond, P95: 40 * time.Millisecond, Max: 40 * time.Millisecond, SlowestTrace: "d"},
+		{Name: "parse", Count: 4, Total: 4 * time.Millisecond, Mean: time.Millisecond, P95: time.Millisecond, Max: time.Millisecond, SlowestTrace: "a"},
+	}
+	if summary := tracer.Summary(); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected %+v, got %+v", expected, summary)
+	}
+
+	var table strings.Builder
+	if err := tracer.WriteSummary(&table); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "STAGE") || !strings.HasPrefix(lines[1], "ttfb") || !strings.HasSuffix(lines[1], "d") {
+		t.Errorf("unexpected summary table:\n%v", table.String())
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Package trends buckets the word counts of articles by their publication date, and computes the
+per-bucket frequency series of the top words, to show how terms trend over days, weeks or months.
+The series are written as CSV (one row per bucket, one column per word) or as JSON.
+*/
+package trends
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"firefly-assignment/utils"
+	"firefly-assignment/wordOps"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Granularities of the buckets.
+const (
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+)
+
+// Granularities lists the supported granularities of the buckets.
+var Granularities = []string{Day, Week, Month}
+
+// MaxBuckets is the maximum number of buckets of a series. Articles whose dates would stretch the series
+// beyond it, such as a wrong date in 1970, are left out as outliers.
+const MaxBuckets = 1000
+
+// Bucket holds the frequencies of the top words in the articles published in a period.
+type Bucket struct {
+	Bucket      string                 `json:"bucket"`
+	Start       string                 `json:"start"`
+	Articles    int                    `json:"articles"`
+	TotalWords  int64                  `json:"total_words"`
+	Frequencies utils.WordFrequencyMap `json:"frequencies"`
+}
+
+// Series is the frequency series of the top words, with one bucket per period from the first to the
+// last publication date, including the periods without articles.
+type Series struct {
+	Granularity string   `json:"granularity"`
+	Words       []string `json:"words"`
+	Buckets     []Bucket `json:"buckets"`
+	Undated     int      `json:"undated_articles"`
+	Outliers    int      `json:"outlier_articles,omitempty"` // Articles left out to keep the series within MaxBuckets
+}
+
+// bucketCounts holds the counts of all words in the articles published in a period.
+type bucketCounts struct {
+	articles int
+	counts   utils.WordFrequencyMap
+}
+
+// Tracker accumulates the word counts of articles by publication period.
+type Tracker struct {
+	granularity string
+	mutex       sync.Mutex
+	buckets     map[time.Time]*bucketCounts
+	undated     int
+}
+
+// NewTracker creates a Tracker that buckets articles with the given granularity.
+//
+// Parameters:
+//   - granularity: The granularity of the buckets, one of Granularities.
+//
+// Returns:
+//   - *Tracker: The Tracker.
+//   - error: An error if the granularity is not supported.
+func NewTracker(granularity string) (*Tracker, error) {
+	switch granularity {
+	case Day, Week, Month:
+		return &Tracker{granularity: granularity, buckets: make(map[time.Time]*bucketCounts)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported trend granularity %q, expected one of %v", granularity, Granularities)
+	}
+}
+
+// Add adds the word counts of an article published at the given time to its bucket.
+// Articles without a publication time are only counted as undated. It is safe to call Add concurrently.
+//
+// Parameters:
+//   - published: The publication time of the article, or the zero time if it is unknown.
+//   - counts: The word counts of the article.
+func (t *Tracker) Add(published time.Time, counts utils.WordFrequencyMap) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if published.IsZero() {
+		t.undated++
+		return
+	}
+
+	start := t.start(published)
+	bucket, exists := t.buckets[start]
+	if !exists {
+		bucket = &bucketCounts{counts: make(utils.WordFrequencyMap)}
+		t.buckets[start] = bucket
+	}
+	bucket.articles++
+	for word, count := range counts {
+		bucket.counts[word] += count
+	}
+}
+
+// Series returns the frequency series of the top n words over all dated articles.
+//
+// Parameters:
+//   - n: The number of top words to follow.
+//
+// Returns:
+//   - Series: The frequency series, in chronological order.
+func (t *Tracker) Series(n int) Series {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	series := Series{Granularity: t.granularity, Words: []string{}, Buckets: []Bucket{}, Undated: t.undated}
+	if len(t.buckets) == 0 {
+		return series
+	}
+
+	starts := make([]time.Time, 0, len(t.buckets))
+	for start := range t.buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	// Drop the buckets farthest from the median date until the series fits in MaxBuckets.
+	median := starts[len(starts)/2]
+	for t.span(starts[0], starts[len(starts)-1]) > MaxBuckets {
+		if median.Sub(starts[0]) > starts[len(starts)-1].Sub(median) {
+			series.Outliers += t.buckets[starts[0]].articles
+			starts = starts[1:]
+		} else {
+			series.Outliers += t.buckets[starts[len(starts)-1]].articles
+			starts = starts[:len(starts)-1]
+		}
+	}
+
+	totals := make(utils.WordFrequencyMap)
+	for _, start := range starts {
+		for word, count := range t.buckets[start].counts {
+			totals[word] += count
+		}
+	}
+
+	for _, word := range wordOps.GetTopNWords(n, totals) {
+		series.Words = append(series.Words, word.Word)
+	}
+
+	for start := starts[0]; !start.After(starts[len(starts)-1]); start = t.next(start) {
+		bucket := Bucket{Bucket: t.label(start), Start: start.Format(time.DateOnly), Frequencies: make(utils.WordFrequencyMap)}
+		if counts, exists := t.buckets[start]; exists {
+			bucket.Articles = counts.articles
+			for _, count := range counts.counts {
+				bucket.TotalWords += int64(count)
+			}
+			for _, word := range series.Words {
+				bucket.Frequencies[word] = counts.counts[word]
+			}
+		} else {
+			for _, word := range series.Words {
+				bucket.Frequencies[word] = 0
+			}
+		}
+		series.Buckets = append(series.Buckets, bucket)
+	}
+	return series
+}
+
+// start returns the start of the bucket of the given time: the day, the Monday of the ISO week, or the first of the month, in UTC.
+func (t *Tracker) start(published time.Time) time.Time {
+	published = published.UTC()
+	day := time.Date(published.Year(), published.Month(), published.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch t.granularity {
+	case Week:
+		// Weeks start on Monday, as ISO weeks do.
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case Month:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// next returns the start of the bucket after the bucket that starts at the given time.
+func (t *Tracker) next(start time.Time) time.Time {
+	switch t.granularity {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// span returns the number of buckets from the bucket that starts at first to the bucket that starts at last, included.
+func (t *Tracker) span(first time.Time, last time.Time) int {
+	switch t.granularity {
+	case Week:
+		return int(last.Sub(first).Hours()/24/7) + 1
+	case Month:
+		return (last.Year()-first.Year())*12 + int(last.Month()-first.Month()) + 1
+	default:
+		return int(last.Sub(first).Hours()/24) + 1
+	}
+}
+
+// label returns the name of the bucket that starts at the given time, such as "2019-08-25", "2019-W34" or "2019-08".
+func (t *Tracker) label(start time.Time) string {
+	switch t.granularity {
+	case Week:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Month:
+		return start.Format("2006-01")
+	default:
+		return start.Format(time.DateOnly)
+	}
+}
+
+// WriteCSV writes the series as CSV, with a header row, one row per bucket and one column per word.
+//
+// Parameters:
+//   - w: The writer to write the series to.
+//
+// Returns:
+//   - error: An error if the series cannot be written.
+func (s Series) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write(append([]string{"bucket", "start", "articles", "total_words"}, s.Words...))
+	for _, bucket := range s.Buckets {
+		row := []string{bucket.Bucket, bucket.Start, strconv.Itoa(bucket.Articles), strconv.FormatInt(bucket.TotalWords, 10)}
+		for _, word := range s.Words {
+			row = append(row, strconv.Itoa(int(bucket.Frequencies[word])))
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the series as pretty-formatted JSON.
+//
+// Parameters:
+//   - w: The writer to write the series to.
+//
+// Returns:
+//   - error: An error if the series cannot be converted to JSON or written.
+func (s Series) WriteJSON(w io.Writer) error {
+	output, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return fmt.Errorf("could not convert trends to JSON: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(output))
+	return err
+}
@@ -0,0 +1,179 @@
+package trends
+
+import (
+	"firefly-assignment/utils"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTracker(t *testing.T, granularity string) *Tracker {
+	tracker, err := NewTracker(granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tracker.Add(time.Date(2019, 8, 24, 12, 0, 0, 0, time.UTC), utils.WordFrequencyMap{"phone": 3, "apple": 1})
+	tracker.Add(time.Date(2019, 8, 25, 0, 0, 0, 0, time.UTC), utils.WordFrequencyMap{"phone": 1, "space": 2})
+	tracker.Add(time.Date(2019, 8, 27, 0, 0, 0, 0, time.UTC), utils.WordFrequencyMap{"space": 4})
+	tracker.Add(time.Date(2019, 9, 2, 0, 0, 0, 0, time.UTC), utils.WordFrequencyMap{"apple": 1})
+	tracker.Add(time.Time{}, utils.WordFrequencyMap{"phone": 10})
+	return tracker
+}
+
+func TestSeries(t *testing.T) {
+	tests := []struct {
+		granularity     string
+		expectedBuckets []string
+		expectedStarts  []string
+		expectedSpace   []int32
+	}{
+		{
+			granularity:     Day,
+			expectedBuckets: []string{"2019-08-24", "2019-08-25", "2019-08-26", "2019-08-27", "2019-08-28", "2019-08-29", "2019-08-30", "2019-08-31", "2019-09-01", "2019-09-02"},
+			expectedStarts:  []string{"2019-08-24", "2019-08-25", "2019-08-26", "2019-08-27", "2019-08-28", "2019-08-29", "2019-08-30", "2019-08-31", "2019-09-01", "2019-09-02"},
+			expectedSpace:   []int32{0, 2, 0, 4, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			// 2019-08-24 and 25 are the weekend of ISO week 34, 2019-08-26 is the Monday of week 35.
+			granularity:     Week,
+			expectedBuckets: []string{"2019-W34", "2019-W35", "2019-W36"},
+			expectedStarts:  []string{"2019-08-19", "2019-08-26", "2019-09-02"},
+			expectedSpace:   []int32{2, 4, 0},
+		},
+		{
+			granularity:     Month,
+			expectedBuckets: []string{"2019-08", "2019-09"},
+			expectedStarts:  []string{"2019-08-01", "2019-09-01"},
+			expectedSpace:   []int32{6, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granularity, func(t *testing.T) {
+			series := newTestTracker(t, tt.granularity).Series(2)
+
+			if !reflect.DeepEqual(series.Words, []string{"space", "phone"}) {
+				t.Errorf("expected the top 2 dated words, got %v", series.Words)
+			}
+			if series.Undated != 1 {
+				t.Errorf("expected 1 undated article, got %v", series.Undated)
+			}
+
+			var buckets, starts []string
+			var space []int32
+			for _, bucket := range series.Buckets {
+				buckets = append(buckets, bucket.Bucket)
+				starts = append(starts, bucket.Start)
+				space = append(space, bucket.Frequencies["space"])
+			}
+			if !reflect.DeepEqual(buckets, tt.expectedBuckets) || !reflect.DeepEqual(starts, tt.expectedStarts) {
+				t.Errorf("expected buckets %v starting %v, got %v starting %v", tt.expectedBuckets, tt.expectedStarts, buckets, starts)
+			}
+			if !reflect.DeepEqual(space, tt.expectedSpace) {
+				t.Errorf("expected series %v, got %v", tt.expectedSpace, space)
+			}
+		})
+	}
+}
+
+func TestSeriesOutliers(t *testing.T) {
+	tests := []struct {
+		name             string
+		granularity      string
+		outlier          time.Time
+		expectedBuckets  int
+		expectedOutliers int
+	}{
+		{name: "Epoch date", granularity: Day, outlier: time.Unix(0, 0), expectedBuckets: 10, expectedOutliers: 1},
+		{name: "Far future date", granularity: Day, outlier: time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC), expectedBuckets: 10, expectedOutliers: 1},
+		{name: "Year 100", granularity: Week, outlier: time.Date(100, 1, 1, 0, 0, 0, 0, time.UTC), expectedBuckets: 3, expectedOutliers: 1},
+		{name: "Date within the maximum buckets", granularity: Month, outlier: time.Unix(0, 0), expectedBuckets: 597, expectedOutliers: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTestTracker(t, tt.granularity)
+			tracker.Add(tt.outlier, utils.WordFrequencyMap{"epoch": 100})
+			series := tracker.Series(2)
+
+			if len(series.Buckets) != tt.expectedBuckets || series.Outliers != tt.expectedOutliers {
+				t.Errorf("expected %v buckets and %v outliers, got %v buckets and %v outliers",
+					tt.expectedBuckets, tt.expectedOutliers, len(series.Buckets), series.Outliers)
+			}
+			if tt.expectedOutliers > 0 && !reflect.DeepEqual(series.Words, []string{"space", "phone"}) {
+				t.Errorf("expected the words of the outliers to be left out, got %v", series.Words)
+			}
+		})
+	}
+}
+
+func TestNewTrackerUnsupported(t *testing.T) {
+	if _, err := NewTracker("year"); err == nil {
+		t.Error("expected error for unsupported granularity")
+	}
+}
+
+func TestWriteSeries(t *testing.T) {
+	series := newTestTracker(t, Month).Series(2)
+
+	tests := []struct {
+		name     string
+		write    func(s Series, output *strings.Builder) error
+		expected string
+	}{
+		{
+			name:     "CSV",
+			write:    func(s Series, output *strings.Builder) error { return s.WriteCSV(output) },
+			expected: "bucket,start,articles,total_words,space,phone\n2019-08,2019-08-01,3,11,6,4\n2019-09,2019-09-01,1,1,0,0\n",
+		},
+		{
+			name:  "JSON",
+			write: func(s Series, output *strings.Builder) error { return s.WriteJSON(output) },
+			expected: `{
+    "granularity": "month",
+    "words": [
+        "space",
+        "phone"
+    ],
+    "buckets": [
+        {
+            "bucket": "2019-08",
+            "start": "2019-08-01",
+            "articles": 3,
+            "total_words": 11,
+            "frequencies": {
+                "phone": 4,
+                "space": 6
+            }
+        },
+        {
+            "bucket": "2019-09",
+            "start": "2019-09-01",
+            "articles": 1,
+            "total_words": 1,
+            "frequencies": {
+                "phone": 0,
+                "space": 0
+            }
+        }
+    ],
+    "undated_articles": 1
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			if err := tt.write(series, &output); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.String() != tt.expected {
+				t.Errorf("expected:\n%v\ngot:\n%v", tt.expected, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package urlSource
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// documentKind is the kind of an XML source, given by its root element.
+type documentKind string
+
+// Kinds of XML sources.
+const (
+	kindSitemap      documentKind = "urlset"
+	kindSitemapIndex documentKind = "sitemapindex"
+	kindRSS          documentKind = "rss"
+	kindRDF          documentKind = "RDF" // RSS 1.0
+	kindAtom         documentKind = "feed"
+)
+
+// linkElements maps each kind of XML source to the element that holds its links, and to the parent of that element.
+var linkElements = map[documentKind][2]string{
+	kindSitemap:      {"url", "loc"},
+	kindSitemapIndex: {"sitemap", "loc"},
+	kindRSS:          {"item", "link"},
+	kindRDF:          {"item", "link"},
+	kindAtom:         {"entry", "link"},
+}
+
+// document holds the links of an XML source.
+type document struct {
+	kind  documentKind
+	links []string
+}
+
+// parseXML parses the links of an XML source: the pages of a sitemap, the sitemaps of a sitemap index,
+// or the items of an RSS or Atom feed. The text of a link element is its link, except in Atom feeds,
+// where the link of an entry is the href of its first link element that is not related otherwise.
+//
+// Parameters:
+//   - content: The XML source.
+//
+// Returns:
+//   - document: The kind and the links of the source.
+//   - error: An error if the source is not valid XML, or not a sitemap or a feed.
+func parseXML(content []byte) (document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	// Feeds are often declared in legacy encodings, whose links are ASCII anyway.
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	var doc document
+	var path []string
+	var text strings.Builder
+	var inLink, linked bool
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return document{}, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if len(path) == 0 {
+				doc.kind = documentKind(element.Name.Local)
+				if _, supported := linkElements[doc.kind]; !supported {
+					return document{}, fmt.Errorf("unsupported XML document <%v>", element.Name.Local)
+				}
+			}
+			parent := ""
+			if len(path) > 0 {
+				parent = path[len(path)-1]
+			}
+			path = append(path, element.Name.Local)
+
+			link := linkElements[doc.kind]
+			if parent == link[0] && element.Name.Local == link[1] {
+				if doc.kind == kindAtom {
+					if href, rel := attribute(element, "href"), attribute(element, "rel"); !linked && href != "" && (rel == "" || rel == "alternate") {
+						doc.links = append(doc.links, href)
+						linked = true
+					}
+					continue
+				}
+				inLink = true
+				text.Reset()
+			}
+			if element.Name.Local == link[0] {
+				linked = false
+			}
+		case xml.CharData:
+			if inLink {
+				text.Write(element)
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			if inLink {
+				if link := strings.TrimSpace(text.String()); link != "" {
+					doc.links = append(doc.links, link)
+				}
+				inLink = false
+			}
+		}
+	}
+
+	if doc.kind == "" {
+		return document{}, fmt.Errorf("empty XML document")
+	}
+	return doc, nil
+}
+
+// attribute returns the value of the attribute of an element with the given name, or "" if it has none.
+func attribute(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package urlSource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseXML(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedKind  documentKind
+		expectedLinks []string
+		expectError   bool
+	}{
+		{
+			name: "Sitemap",
+			content: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>
+    https://example.com/b?x=1&amp;y=2
+  </loc></url>
+</urlset>`,
+			expectedKind:  kindSitemap,
+			expectedLinks: []string{"https://example.com/a", "https://example.com/b?x=1&y=2"},
+		},
+		{
+			name: "Sitemap index",
+			content: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-1.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/sitemap-2.xml.gz</loc></sitemap>
+</sitemapindex>`,
+			expectedKind:  kindSitemapIndex,
+			expectedLinks: []string{"https://example.com/sitemap-1.xml", "https://example.com/sitemap-2.xml.gz"},
+		},
+		{
+			name: "RSS feed",
+			content: `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <link>https://example.com/</link>
+    <atom:link href="https://example.com/feed" rel="self"/>
+    <item><title>A</title><link>https://example.com/a</link></item>
+    <item><title>B</title><link><![CDATA[https://example.com/b]]></link></item>
+  </channel>
+</rss>`,
+			expectedKind:  kindRSS,
+			expectedLinks: []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name: "Atom feed",
+			content: `<feed xmlns="http://www.w3.org/2005/Atom">
+  <link href="https://example.com/"/>
+  <entry>
+    <link rel="edit" href="https://example.com/edit/a"/>
+    <link rel="alternate" href="https://example.com/a"/>
+    <link href="https://example.com/a-again"/>
+  </entry>
+  <entry><link href="https://example.com/b"/></entry>
+</feed>`,
+			expectedKind:  kindAtom,
+			expectedLinks: []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name:        "Unsupported document",
+			content:     `<html><body><a href="https://example.com/a">A</a></body></html>`,
+			expectError: true,
+		},
+		{
+			name:        "Invalid XML",
+			content:     `<urlset><url><loc>https://example.com/a</url>`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseXML([]byte(tt.content))
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", doc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if doc.kind != tt.expectedKind || !reflect.DeepEqual(doc.links, tt.expectedLinks) {
+				t.Errorf("expected %v %v, got %v %v", tt.expectedKind, tt.expectedLinks, doc.kind, doc.links)
+			}
+		})
+	}
+}
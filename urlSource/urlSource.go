@@ -0,0 +1,255 @@
+/*
+Package urlSource reads the URLs of a job from its sources, which can be mixed freely:
+  - '-' for the standard input,
+  - paths of files, or glob patterns matching several files,
+  - URLs of remote lists, fetched with the job's fetcher.
+
+Each source is either a list of URLs, one per line, where blank lines and lines starting with '#' are skipped,
+an XML sitemap (or a sitemap index, whose sitemaps are read in turn), or an RSS or Atom feed, whose items are read.
+Sitemaps may be gzipped. The URLs of all the sources are canonicalized, and read only once, in their first order.
+*/
+package urlSource
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxSitemapDepth is the default number of nested sitemap indexes that are followed.
+const DefaultMaxSitemapDepth = 3
+
+// ErrNoFetcher is returned when a remote source is read without a fetcher.
+var ErrNoFetcher = errors.New("no fetcher for remote sources")
+
+// FetchFunc fetches the content of a remote source.
+type FetchFunc func(url string) (string, error)
+
+// Reader reads the URLs of sources.
+type Reader struct {
+	fetch           FetchFunc
+	stdin           io.Reader
+	maxSitemapDepth int
+}
+
+// Option configures a Reader.
+type Option func(r *Reader)
+
+// WithFetch sets the function that fetches remote sources and the sitemaps of sitemap indexes.
+func WithFetch(fetch FetchFunc) Option {
+	return func(r *Reader) {
+		r.fetch = fetch
+	}
+}
+
+// WithStdin sets the reader of the '-' source.
+func WithStdin(stdin io.Reader) Option {
+	return func(r *Reader) {
+		r.stdin = stdin
+	}
+}
+
+// WithMaxSitemapDepth sets the number of nested sitemap indexes that are followed.
+func WithMaxSitemapDepth(depth int) Option {
+	return func(r *Reader) {
+		r.maxSitemapDepth = depth
+	}
+}
+
+// New creates a Reader of local sources and stdin, overridden by the given options.
+//
+// Parameters:
+//   - opts: The options to apply to the Reader.
+//
+// Returns:
+//   - *Reader: The Reader.
+func New(opts ...Option) *Reader {
+	r := &Reader{stdin: os.Stdin, maxSitemapDepth: DefaultMaxSitemapDepth}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Read reads the URLs of the given sources, in order and without duplicates.
+//
+// Parameters:
+//   - sources: The sources: '-' for stdin, file paths, glob patterns or URLs.
+//
+// Returns:
+//   - []string: The canonical URLs.
+//   - error: An error if a source cannot be read.
+func (r *Reader) Read(sources []string) ([]string, error) {
+	var urls []string
+	seen := make(map[string]bool)
+	duplicates := 0
+	add := func(rawURL string) {
+		canonical := Canonicalize(rawURL)
+		if seen[canonical] {
+			duplicates++
+			return
+		}
+		seen[canonical] = true
+		urls = append(urls, canonical)
+	}
+
+	for _, source := range sources {
+		paths, err := r.expand(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			if err := r.readSource(path, 0, add); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if duplicates > 0 {
+		slog.Debug("Skipped duplicate URLs", "duplicates", duplicates)
+	}
+	return urls, nil
+}
+
+// expand returns the paths of the files that match a glob pattern, sorted by name, or the source itself otherwise.
+func (r *Reader) expand(source string) ([]string, error) {
+	if source == "-" || isRemote(source) || !strings.ContainsAny(source, "*?[") {
+		return []string{source}, nil
+	}
+
+	paths, err := filepath.Glob(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", source, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match %q", source)
+	}
+	return paths, nil
+}
+
+// readSource reads the URLs of a source, following the sitemaps of a sitemap index up to the maximum depth.
+func (r *Reader) readSource(source string, depth int, add func(string)) error {
+	content, err := r.load(source)
+	if err != nil {
+		return err
+	}
+	content, err = decompress(content)
+	if err != nil {
+		return fmt.Errorf("could not decompress %v: %w", source, err)
+	}
+
+	trimmed := bytes.TrimLeft(content, "\ufeff \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		return readList(content, add)
+	}
+
+	document, err := parseXML(trimmed)
+	if err != nil {
+		return fmt.Errorf("could not parse %v: %w", source, err)
+	}
+	if document.kind != kindSitemapIndex {
+		for _, link := range document.links {
+			add(link)
+		}
+		return nil
+	}
+
+	if depth >= r.maxSitemapDepth {
+		slog.Warn("Skipping nested sitemap index", "source", source, "depth", depth)
+		return nil
+	}
+	for _, sitemap := range document.links {
+		// A sitemap that cannot be read is skipped, so that the other sitemaps of the index are still read.
+		if err := r.readSource(sitemap, depth+1, add); err != nil {
+			slog.Warn("Skipping sitemap", "sitemap", sitemap, "error", err)
+		}
+	}
+	return nil
+}
+
+// load returns the content of a source: stdin, a remote source or a file.
+func (r *Reader) load(source string) ([]byte, error) {
+	if source == "-" {
+		return io.ReadAll(r.stdin)
+	}
+	if isRemote(source) {
+		if r.fetch == nil {
+			return nil, fmt.Errorf("could not fetch %v: %w", source, ErrNoFetcher)
+		}
+		content, err := r.fetch(source)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch %v: %w", source, err)
+		}
+		return []byte(content), nil
+	}
+	return os.ReadFile(source)
+}
+
+// isRemote reports whether a source is the URL of a remote source.
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// decompress decompresses gzipped content, such as a sitemap.xml.gz, and returns other content as is.
+func decompress(content []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		return content, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// readList reads a list of URLs, one per line, skipping blank lines and '#' comments.
+func readList(content []byte, add func(string)) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		add(line)
+	}
+	return scanner.Err()
+}
+
+// Canonicalize returns the canonical form of a URL, so that the same page is fetched only once:
+// the scheme and host are lowercased, the default port and the fragment are removed, and an empty path becomes '/'.
+// A URL that is not absolute is returned as is.
+//
+// Parameters:
+//   - rawURL: The URL.
+//
+// Returns:
+//   - string: The canonical URL.
+func Canonicalize(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return rawURL
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if port := parsed.Port(); (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+		parsed.Host = strings.TrimSuffix(parsed.Host, ":"+port)
+	}
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	parsed.ForceQuery = false
+	return parsed.String()
+}
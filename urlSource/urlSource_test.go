@@ -0,0 +1,160 @@
+package urlSource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "Canonical URL", url: "https://example.com/a?b=1", expected: "https://example.com/a?b=1"},
+		{name: "Uppercase scheme and host", url: "HTTPS://Example.COM/Path", expected: "https://example.com/Path"},
+		{name: "Default HTTP port", url: "http://example.com:80/a", expected: "http://example.com/a"},
+		{name: "Default HTTPS port", url: "https://example.com:443/a", expected: "https://example.com/a"},
+		{name: "Other port", url: "https://example.com:8443/a", expected: "https://example.com:8443/a"},
+		{name: "Empty path", url: "https://example.com", expected: "https://example.com/"},
+		{name: "Fragment", url: "https://example.com/a#comments", expected: "https://example.com/a"},
+		{name: "Empty query", url: "https://example.com/a?", expected: "https://example.com/a"},
+		{name: "Relative URL", url: "example.com/a", expected: "example.com/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Canonicalize(tt.url); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// gzipped compresses content, like a sitemap.xml.gz.
+func gzipped(t *testing.T, content string) string {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writer.Close()
+	return buffer.String()
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"list-1.txt":  "# Articles\nhttps://example.com/a\n\n  https://example.com/b  \r\n",
+		"list-2.txt":  "https://EXAMPLE.com/b#top\nhttps://example.com/c\n",
+		"sitemap.xml": `<urlset><url><loc>https://example.com/c</loc></url><url><loc>https://example.com/d</loc></url></urlset>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	remote := map[string]string{
+		"https://example.com/sitemap.xml": `<sitemapindex>
+  <sitemap><loc>https://example.com/sitemap-1.xml.gz</loc></sitemap>
+  <sitemap><loc>https://example.com/missing.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/sitemap-index.xml</loc></sitemap>
+</sitemapindex>`,
+		"https://example.com/sitemap-1.xml.gz":  gzipped(t, `<urlset><url><loc>https://example.com/e</loc></url></urlset>`),
+		"https://example.com/sitemap-index.xml": `<sitemapindex><sitemap><loc>https://example.com/sitemap-2.xml</loc></sitemap></sitemapindex>`,
+		"https://example.com/sitemap-2.xml":     `<urlset><url><loc>https://example.com/f</loc></url></urlset>`,
+		"https://example.com/feed":              `<rss><channel><item><link>https://example.com/g</link></item></channel></rss>`,
+	}
+	fetch := func(url string) (string, error) {
+		if content, exists := remote[url]; exists {
+			return content, nil
+		}
+		return "", fmt.Errorf("not found: %v", url)
+	}
+
+	tests := []struct {
+		name          string
+		sources       []string
+		stdin         string
+		opts          []Option
+		expected      []string
+		expectedError error
+	}{
+		{
+			name:     "List with comments, blanks and whitespace",
+			sources:  []string{filepath.Join(dir, "list-1.txt")},
+			expected: []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name:     "Several files without duplicates",
+			sources:  []string{filepath.Join(dir, "list-1.txt"), filepath.Join(dir, "list-2.txt")},
+			expected: []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"},
+		},
+		{
+			name:     "Glob pattern",
+			sources:  []string{filepath.Join(dir, "list-*.txt")},
+			expected: []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"},
+		},
+		{
+			name:     "Stdin and a local sitemap",
+			sources:  []string{"-", filepath.Join(dir, "sitemap.xml")},
+			stdin:    "https://example.com/a\n",
+			expected: []string{"https://example.com/a", "https://example.com/c", "https://example.com/d"},
+		},
+		{
+			name:     "Remote sitemap index and feed",
+			sources:  []string{"https://example.com/sitemap.xml", "https://example.com/feed"},
+			opts:     []Option{WithFetch(fetch)},
+			expected: []string{"https://example.com/e", "https://example.com/f", "https://example.com/g"},
+		},
+		{
+			name:     "Nested sitemap indexes beyond the maximum depth",
+			sources:  []string{"https://example.com/sitemap.xml"},
+			opts:     []Option{WithFetch(fetch), WithMaxSitemapDepth(1)},
+			expected: []string{"https://example.com/e"},
+		},
+		{
+			name:          "Remote source without a fetcher",
+			sources:       []string{"https://example.com/feed"},
+			expectedError: ErrNoFetcher,
+		},
+		{
+			name:          "Missing file",
+			sources:       []string{filepath.Join(dir, "missing.txt")},
+			expectedError: os.ErrNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := New(append([]Option{WithStdin(strings.NewReader(tt.stdin))}, tt.opts...)...)
+			urls, err := reader.Read(tt.sources)
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(urls, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, urls)
+			}
+		})
+	}
+
+	t.Run("Glob pattern without matches", func(t *testing.T) {
+		if _, err := New().Read([]string{filepath.Join(dir, "*.csv")}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
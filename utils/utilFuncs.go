@@ -1,7 +1,11 @@
 // Package utils provides utility functions for common operations used throughout the application.
 package utils
 
-import "unicode"
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 // IsLetter checks whether all characters in the provided string are letters.
 // It returns true if all runes in the string belong to the Unicode letter category ("L").
@@ -19,3 +23,37 @@ func IsLetter(s string) bool {
 	}
 	return true
 }
+
+// TrimNonLetters removes any leading and trailing characters that are not letters or digits,
+// such as punctuation and quotes surrounding a word.
+//
+// Parameters:
+//   - s: The string to trim.
+//
+// Returns:
+//   - string: The trimmed string.
+func TrimNonLetters(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Truncate shortens a string to at most the given number of bytes, without splitting a multi-byte character,
+// and marks the cut with an ellipsis.
+//
+// Parameters:
+//   - s: The string to truncate.
+//   - maxLength: The maximum length of the string, in bytes, before the ellipsis.
+//
+// Returns:
+//   - string: The string itself if it is short enough, or its truncated beginning followed by "...".
+func Truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -8,3 +8,10 @@ type WordFreq struct {
 	Word      string
 	Frequency int32
 }
+
+type WordScore struct {
+	Word  string
+	Score float64
+}
+
+type LanguageWordBanks = map[string]WordBank
@@ -56,3 +56,84 @@ func TestIsLetter(t *testing.T) {
 		})
 	}
 }
+
+func TestTrimNonLetters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Trailing punctuation",
+			input:    "words.",
+			expected: "words",
+		},
+		{
+			name:     "Surrounding quotes",
+			input:    "\"quoted\"",
+			expected: "quoted",
+		},
+		{
+			name:     "Inner punctuation is kept",
+			input:    "(Apple's)",
+			expected: "Apple's",
+		},
+		{
+			name:     "Only punctuation",
+			input:    "--",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TrimNonLetters(tt.input)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxLength int
+		expected  string
+	}{
+		{
+			name:      "Short string",
+			input:     "words",
+			maxLength: 10,
+			expected:  "words",
+		},
+		{
+			name:      "Long string",
+			input:     "many words",
+			maxLength: 4,
+			expected:  "many...",
+		},
+		{
+			name:      "Cut inside a multi-byte character",
+			input:     "Straße",
+			maxLength: 5,
+			expected:  "Stra...",
+		},
+		{
+			name:      "Cut after a multi-byte character",
+			input:     "Straße",
+			maxLength: 6,
+			expected:  "Straß...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Truncate(tt.input, tt.maxLength)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
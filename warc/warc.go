@@ -0,0 +1,317 @@
+/*
+Package warc reads and writes WARC 1.1 (Web ARChive) files, the ISO 28500 format used to archive
+raw HTTP exchanges. Archiving the requests and responses of a run makes the run reproducible:
+the responses can be replayed later without touching the network.
+Files whose name ends with ".gz" are compressed record by record, as is usual for WARC files.
+*/
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the version line that starts every record.
+const Version = "WARC/1.1"
+
+// Record types used by this package.
+const (
+	TypeWarcinfo = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+)
+
+// Record is a WARC record: its named header fields and its content block.
+type Record struct {
+	Header  textproto.MIMEHeader
+	Content []byte
+}
+
+// Type returns the WARC-Type of the record.
+func (r *Record) Type() string {
+	return r.Header.Get("WARC-Type")
+}
+
+// TargetURI returns the WARC-Target-URI of the record.
+func (r *Record) TargetURI() string {
+	return r.Header.Get("WARC-Target-URI")
+}
+
+// Writer writes WARC records. It is safe to use a Writer concurrently.
+type Writer struct {
+	w        io.Writer
+	compress bool
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+// NewWriter creates a Writer that writes records to w, compressing each record in its own gzip member if compress is set.
+//
+// Parameters:
+//   - w: The writer to write the records to.
+//   - compress: Whether to compress the records.
+//
+// Returns:
+//   - *Writer: The Writer.
+func NewWriter(w io.Writer, compress bool) *Writer {
+	return &Writer{w: w, compress: compress, now: time.Now}
+}
+
+// WriteRecord writes a record with the given type and extra header fields. The WARC-Record-ID, WARC-Date,
+// Content-Length and WARC-Block-Digest fields are set by the Writer.
+//
+// Parameters:
+//   - recordType: The WARC-Type of the record.
+//   - fields: The extra header fields, such as WARC-Target-URI and Content-Type.
+//   - content: The content block of the record.
+//
+// Returns:
+//   - string: The WARC-Record-ID of the written record.
+//   - error: An error if the record cannot be written.
+func (w *Writer) WriteRecord(recordType string, fields map[string]string, content []byte) (string, error) {
+	recordID, err := newRecordID()
+	if err != nil {
+		return "", err
+	}
+
+	var record bytes.Buffer
+	record.WriteString(Version + "\r\n")
+	writeField(&record, "WARC-Type", recordType)
+	writeField(&record, "WARC-Record-ID", recordID)
+	writeField(&record, "WARC-Date", w.now().UTC().Format(time.RFC3339Nano))
+	for _, name := range sortedKeys(fields) {
+		writeField(&record, name, fields[name])
+	}
+	writeField(&record, "WARC-Block-Digest", digest(content))
+	writeField(&record, "Content-Length", strconv.Itoa(len(content)))
+	record.WriteString("\r\n")
+	record.Write(content)
+	record.WriteString("\r\n\r\n")
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.compress {
+		_, err = w.w.Write(record.Bytes())
+	} else {
+		gzipWriter := gzip.NewWriter(w.w)
+		if _, err = gzipWriter.Write(record.Bytes()); err == nil {
+			err = gzipWriter.Close()
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not write WARC record: %w", err)
+	}
+	return recordID, nil
+}
+
+// WriteWarcinfo writes a warcinfo record describing the software that created the file.
+//
+// Parameters:
+//   - fields: The information fields, such as "software" and "format".
+//
+// Returns:
+//   - error: An error if the record cannot be written.
+func (w *Writer) WriteWarcinfo(fields map[string]string) error {
+	var content bytes.Buffer
+	for _, name := range sortedKeys(fields) {
+		writeField(&content, name, fields[name])
+	}
+	_, err := w.WriteRecord(TypeWarcinfo, map[string]string{"Content-Type": "application/warc-fields"}, content.Bytes())
+	return err
+}
+
+// Archive writes a request record and the response record of a raw HTTP exchange.
+//
+// Parameters:
+//   - url: The URL that was requested.
+//   - request: The raw HTTP request, with its headers.
+//   - response: The raw HTTP response, with its headers and body.
+//
+// Returns:
+//   - error: An error if the records cannot be written.
+func (w *Writer) Archive(url string, request []byte, response []byte) error {
+	requestID, err := w.WriteRecord(TypeRequest, map[string]string{
+		"WARC-Target-URI": url,
+		"Content-Type":    "application/http;msgtype=request",
+	}, request)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.WriteRecord(TypeResponse, map[string]string{
+		"WARC-Target-URI":    url,
+		"WARC-Concurrent-To": requestID,
+		"Content-Type":       "application/http;msgtype=response",
+	}, response)
+	return err
+}
+
+// Reader reads WARC records.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader creates a Reader that reads records from r, which may be compressed with gzip.
+//
+// Parameters:
+//   - r: The reader to read the records from.
+//
+// Returns:
+//   - *Reader: The Reader.
+//   - error: An error if the gzip header is invalid.
+func NewReader(r io.Reader) (*Reader, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("could not read compressed WARC file: %w", err)
+		}
+		// Each record is its own gzip member; the reader reads through all of them.
+		return &Reader{r: bufio.NewReader(gzipReader)}, nil
+	}
+	return &Reader{r: buffered}, nil
+}
+
+// Next reads the next record.
+//
+// Returns:
+//   - *Record: The record.
+//   - error: io.EOF if there are no more records, or an error if the record is invalid.
+func (r *Reader) Next() (*Record, error) {
+	// Skip the blank lines that separate the records.
+	var version string
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && strings.TrimSpace(line) == "" {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("could not read WARC record: %w", err)
+		}
+		if version = strings.TrimSpace(line); version != "" {
+			break
+		}
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, fmt.Errorf("invalid WARC record version line: %q", version)
+	}
+
+	header, err := textproto.NewReader(r.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("could not read WARC record header: %w", err)
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid WARC record Content-Length: %q", header.Get("Content-Length"))
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r.r, content); err != nil {
+		return nil, fmt.Errorf("could not read WARC record content: %w", err)
+	}
+
+	return &Record{Header: header, Content: content}, nil
+}
+
+// ErrNotResponse is returned by ResponseBody for records that are not HTTP responses.
+var ErrNotResponse = errors.New("record is not an HTTP response")
+
+// ResponseBody parses the HTTP response archived in a response record, and returns its status code and body.
+//
+// Parameters:
+//   - record: A response record.
+//
+// Returns:
+//   - int: The HTTP status code of the response.
+//   - string: The body of the response.
+//   - error: ErrNotResponse if the record is not a response, or an error if the response cannot be parsed.
+func ResponseBody(record *Record) (int, string, error) {
+	if record.Type() != TypeResponse {
+		return 0, "", ErrNotResponse
+	}
+
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(record.Content)), nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("could not parse archived response of %v: %w", record.TargetURI(), err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, "", fmt.Errorf("could not read archived response of %v: %w", record.TargetURI(), err)
+	}
+	return response.StatusCode, string(body), nil
+}
+
+// Create creates the WARC file at the given path and writes a warcinfo record to it.
+// The records are compressed if the path ends with ".gz".
+//
+// Parameters:
+//   - path: The path of the WARC file.
+//   - info: The fields of the warcinfo record.
+//
+// Returns:
+//   - *Writer: The Writer of the file.
+//   - *os.File: The file, to be closed once all records are written.
+//   - error: An error if the file cannot be created or written.
+func Create(path string, info map[string]string) (*Writer, *os.File, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create WARC file: %w", err)
+	}
+
+	writer := NewWriter(file, strings.HasSuffix(path, ".gz"))
+	if err := writer.WriteWarcinfo(info); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return writer, file, nil
+}
+
+// writeField writes a "Name: value" header line.
+func writeField(buffer *bytes.Buffer, name string, value string) {
+	buffer.WriteString(name + ": " + value + "\r\n")
+}
+
+// newRecordID returns a new random record ID, as a URN of a version 4 UUID.
+func newRecordID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", fmt.Errorf("could not generate WARC record ID: %w", err)
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 // Version 4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // Variant 10
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]), nil
+}
+
+// digest returns the SHA-1 digest of the content in the usual "sha1:BASE32" notation of WARC files.
+func digest(content []byte) string {
+	sum := sha1.Sum(content)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// sortedKeys returns the keys of the fields in sorted order, so that records are written deterministically.
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
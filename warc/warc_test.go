@@ -0,0 +1,114 @@
+package warc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	request := "GET /article HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	response := "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nContent-Length: 21\r\n\r\n<html>article</html>\n"
+
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{name: "Uncompressed", compress: false},
+		{name: "Compressed", compress: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var file bytes.Buffer
+			writer := NewWriter(&file, tt.compress)
+			if err := writer.WriteWarcinfo(map[string]string{"software": "firefly"}); err != nil {
+				t.Fatalf("unexpected error writing warcinfo: %v", err)
+			}
+			if err := writer.Archive("https://example.com/article", []byte(request), []byte(response)); err != nil {
+				t.Fatalf("unexpected error archiving: %v", err)
+			}
+
+			if tt.compress == strings.HasPrefix(file.String(), Version) {
+				t.Errorf("expected compressed: %v, got file starting with %q", tt.compress, file.String()[:8])
+			}
+
+			reader, err := NewReader(&file)
+			if err != nil {
+				t.Fatalf("unexpected error opening reader: %v", err)
+			}
+
+			var records []*Record
+			for {
+				record, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unexpected error reading record: %v", err)
+				}
+				records = append(records, record)
+			}
+
+			if len(records) != 3 {
+				t.Fatalf("expected 3 records, got %d", len(records))
+			}
+			expectedTypes := []string{TypeWarcinfo, TypeRequest, TypeResponse}
+			for i, record := range records {
+				if record.Type() != expectedTypes[i] {
+					t.Errorf("expected record %d of type %v, got %v", i, expectedTypes[i], record.Type())
+				}
+				if !strings.HasPrefix(record.Header.Get("WARC-Record-ID"), "<urn:uuid:") {
+					t.Errorf("expected a UUID record ID, got %q", record.Header.Get("WARC-Record-ID"))
+				}
+				if record.Header.Get("WARC-Block-Digest") != digest(record.Content) {
+					t.Errorf("expected the block digest to match the content of record %d", i)
+				}
+			}
+
+			if records[2].Header.Get("WARC-Concurrent-To") != records[1].Header.Get("WARC-Record-ID") {
+				t.Error("expected the response to refer to its request")
+			}
+			if string(records[1].Content) != request || records[2].TargetURI() != "https://example.com/article" {
+				t.Errorf("unexpected request record: %v %q", records[1].Header, records[1].Content)
+			}
+
+			status, body, err := ResponseBody(records[2])
+			if err != nil {
+				t.Fatalf("unexpected error parsing response: %v", err)
+			}
+			if status != 200 || body != "<html>article</html>\n" {
+				t.Errorf("expected status 200 and the article, got %d %q", status, body)
+			}
+
+			if _, _, err := ResponseBody(records[1]); !errors.Is(err, ErrNotResponse) {
+				t.Errorf("expected ErrNotResponse for a request record, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Not a WARC file", input: "<html></html>\n"},
+		{name: "Missing Content-Length", input: "WARC/1.1\r\nWARC-Type: response\r\n\r\n"},
+		{name: "Truncated content", input: "WARC/1.1\r\nWARC-Type: response\r\nContent-Length: 100\r\n\r\nshort"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error opening reader: %v", err)
+			}
+			if _, err := reader.Next(); err == nil || err == io.EOF {
+				t.Errorf("expected an error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package wordBank
+
+import (
+	"firefly-assignment/utils"
+	"strings"
+)
+
+// stopwords contains the most common function words for each supported language. Only words
+// longer than 3 characters are listed, since shorter words never make it into a word bank.
+var stopwords = map[string]string{
+	"en": `about above after again against also because been before being below between both
+		could does doing down during each from further have having here into itself just more
+		most other over same should some such than that their theirs them themselves then there
+		these they this those through under until very what when where which while will with
+		would your yours yourself`,
+	"de": `aber alle allem allen aller alles auch auf aus bei beim bist dass dein deine dem den
+		denn der des dich die dies diese diesem diesen dieser dieses doch dort durch eine einem
+		einen einer eines euch euer eure gegen habe haben hatte hier hinter ihre ihrem ihren ihrer
+		jede jedem jeden jeder jedes jene jetzt kann kein keine mein meine mich mir mit nach nicht
+		noch nur oder ohne schon sehr sein seine sich sind über unter viel vom von vor wann warum
+		was weil welche wenn werden wieder wird wurde zum zur zwischen`,
+	"fr": `alors aussi autre avant avec avoir bien cela celle celui cette ceux chaque comme dans
+		depuis donc elle elles encore entre être leur leurs mais même moins notre nous parce pendant
+		peut plus pour quand que quel quelle quelles quels sans selon sont sous tout toute toutes
+		tous très vers votre vous`,
+	"es": `ante antes aquel aquella cada como contra cual cuando desde donde durante ella ellas
+		ellos entre esta estaba estas este esto estos hasta hay mismo mucho muy nada otro otros para
+		pero porque puede sobre también tanto todo todos tras unos usted`,
+	"it": `anche ancora avere come con contro dalla dalle degli della delle dello dopo essere
+		fino hanno loro molto nella nelle negli nostro ogni oppure perché più poco prima quale
+		quando quello questa questo sono sopra sotto stato tanto tutti tutto verso`,
+	"nl": `alle alles andere bijna daar dan dat deze dezelfde dit door echter eens hebben heeft
+		hier hoe hun maar meer met naar niet nog omdat onder ook over tegen toch tot tussen voor
+		waar want werd wordt worden zich zijn zo zonder zou`,
+	"pt": `ainda além antes aquela aquele assim até cada como contra desde depois durante ela
+		elas ele eles entre essa esse esta este isso isto mais mesmo muito nada nem para pela pelo
+		pelos porque quando sem sobre também tanto todo todos uma umas uns`,
+}
+
+// Stopwords returns the set of stopwords for the given language.
+//
+// Parameters:
+//   - language: The ISO 639-1 code of the language.
+//
+// Returns:
+//   - utils.WordBank: The set of stopwords, empty if the language has no stopword list.
+func Stopwords(language string) utils.WordBank {
+	stopwordSet := make(utils.WordBank)
+	for _, word := range strings.Fields(stopwords[language]) {
+		stopwordSet[word] = struct{}{}
+	}
+	return stopwordSet
+}
+
+// WithoutStopwords returns a copy of the word bank that excludes the stopwords of the given language.
+//
+// Parameters:
+//   - wordBank: The word bank to filter.
+//   - language: The ISO 639-1 code of the language of the word bank.
+//
+// Returns:
+//   - utils.WordBank: A new word bank without the stopwords.
+func WithoutStopwords(wordBank utils.WordBank, language string) utils.WordBank {
+	stopwordSet := Stopwords(language)
+	filtered := make(utils.WordBank, len(wordBank))
+	for word := range wordBank {
+		if _, isStopword := stopwordSet[word]; !isStopword {
+			filtered[word] = struct{}{}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,48 @@
+package wordBank
+
+import (
+	"firefly-assignment/utils"
+	"testing"
+)
+
+func TestWithoutStopwords(t *testing.T) {
+	tests := []struct {
+		name          string
+		wordBank      utils.WordBank
+		language      string
+		expectedWords []string
+	}{
+		{
+			name:          "English stopwords are removed",
+			wordBank:      utils.WordBank{"apple": {}, "that": {}, "with": {}, "phone": {}},
+			language:      "en",
+			expectedWords: []string{"apple", "phone"},
+		},
+		{
+			name:          "German stopwords are removed",
+			wordBank:      utils.WordBank{"dass": {}, "telefon": {}, "nicht": {}},
+			language:      "de",
+			expectedWords: []string{"telefon"},
+		},
+		{
+			name:          "Unknown language keeps all words",
+			wordBank:      utils.WordBank{"that": {}, "phone": {}},
+			language:      "xx",
+			expectedWords: []string{"that", "phone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := WithoutStopwords(tt.wordBank, tt.language)
+			if len(result) != len(tt.expectedWords) {
+				t.Fatalf("expected %d words, got %d (%v)", len(tt.expectedWords), len(result), result)
+			}
+			for _, word := range tt.expectedWords {
+				if _, exists := result[word]; !exists {
+					t.Errorf("expected word %s in word bank, but it was not found", word)
+				}
+			}
+		})
+	}
+}
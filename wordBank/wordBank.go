@@ -3,49 +3,200 @@
 package wordBank
 
 import (
-	"firefly-assignment/config"
+	"bufio"
 	"firefly-assignment/utils"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 	"unicode/utf8"
 )
 
-var (
-	wordBankURL string
-)
+// DefaultURL is the URL of the default (English) word bank.
+const DefaultURL = "https://raw.githubusercontent.com/dwyl/english-words/master/words.txt"
+
+// defaultLanguage is the language of the word bank configured with `word_bank_url`.
+const defaultLanguage = "en"
+
+// Bank fetches and validates word banks from their configured sources.
+type Bank struct {
+	url             string
+	languageURLs    map[string]string
+	removeStopwords bool
+	httpGet         func(url string) (*http.Response, error)
+}
+
+// BankOption configures a Bank.
+type BankOption func(b *Bank)
+
+// WithURL sets the URL or file path of the default (English) word bank.
+func WithURL(url string) BankOption {
+	return func(b *Bank) { b.url = url }
+}
+
+// WithLanguageURLs sets the URLs or file paths of the word banks of other languages, keyed by ISO 639-1 code.
+func WithLanguageURLs(languageURLs map[string]string) BankOption {
+	return func(b *Bank) { b.languageURLs = languageURLs }
+}
+
+// WithStopwordsRemoved sets whether the stopwords of each language are removed from its word bank.
+func WithStopwordsRemoved(removeStopwords bool) BankOption {
+	return func(b *Bank) { b.removeStopwords = removeStopwords }
+}
+
+// WithHTTPGet sets the function used to fetch word banks over HTTP, to allow for mocking in test.
+func WithHTTPGet(httpGet func(url string) (*http.Response, error)) BankOption {
+	return func(b *Bank) { b.httpGet = httpGet }
+}
+
+// NewBank creates a Bank with the default settings, overridden by the given options.
+//
+// Parameters:
+//   - opts: The options to apply to the Bank.
+//
+// Returns:
+//   - *Bank: The configured Bank.
+func NewBank(opts ...BankOption) *Bank {
+	b := &Bank{url: DefaultURL, httpGet: http.Get}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
 
 // Initialize fetches the word bank from a configured URL, filters the words based on
 // predefined rules (e.g., words longer than 3 characters and composed of letters),
-// and sends the result through the provided channel.
+// and sends the result through the provided channel. Nothing is sent if the word bank cannot be loaded.
 //
 // Parameters:
 //   - wordBankChannel: A channel to which the validated word bank will be sent.
 //
 // Returns:
 //   - error: An error if the word bank cannot be fetched or processed.
-func Initialize(wordBankChannel chan utils.WordBank) error {
-	wordBankMap := make(utils.WordBank)
-	wordBankURL = config.AppConfig.WordBankURL
-	resp, err := http.Get(wordBankURL)
+func (b *Bank) Initialize(wordBankChannel chan utils.WordBank) error {
+	wordBankMap, err := b.Load(b.url)
 	if err != nil {
-		log.Fatalf("[ERROR] - Error fetching wordbank source %v - %v", wordBankURL, err)
+		return err
+	}
+
+	wordBankChannel <- wordBankMap
+	return nil
+}
+
+// InitializeLanguages fetches the word banks of all configured languages: the default (English)
+// word bank, and one word bank per language from the language URLs.
+// If stopword removal is enabled, the stopwords of each language are removed from its word bank.
+// The result is sent through the provided channel. Nothing is sent if a word bank cannot be loaded.
+//
+// Parameters:
+//   - wordBanksChannel: A channel to which the validated word banks, keyed by language, will be sent.
+//
+// Returns:
+//   - error: An error if a word bank cannot be fetched or processed.
+func (b *Bank) InitializeLanguages(wordBanksChannel chan utils.LanguageWordBanks) error {
+	urls := map[string]string{defaultLanguage: b.url}
+	for language, url := range b.languageURLs {
+		urls[strings.ToLower(language)] = url
+	}
+
+	wordBanks := make(utils.LanguageWordBanks, len(urls))
+	for language, url := range urls {
+		wordBankMap, err := b.Load(url)
+		if err != nil {
+			return fmt.Errorf("could not load the %v word bank: %w", language, err)
+		}
+
+		if b.removeStopwords {
+			wordBankMap = WithoutStopwords(wordBankMap, language)
+		}
+		wordBanks[language] = wordBankMap
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	wordBanksChannel <- wordBanks
+	return nil
+}
+
+// Load reads a newline or whitespace separated list of words from the given source, which is either
+// an HTTP(S) URL or a local file path, and keeps the words that are longer than 3 characters and
+// composed of letters.
+//
+// Parameters:
+//   - source: The URL or file path of the word list.
+//
+// Returns:
+//   - utils.WordBank: The set of valid, lowercased words.
+//   - error: An error if the word list cannot be fetched (including a non-2xx status) or read.
+func (b *Bank) Load(source string) (utils.WordBank, error) {
+	var reader io.ReadCloser
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := b.httpGet(source)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching wordbank source %v: %w", source, err)
+		}
+		// An error page would otherwise be parsed as the word list, and count almost nothing.
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error fetching wordbank source %v: %v", source, resp.Status)
+		}
+		reader = resp.Body
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("error opening wordbank source %v: %w", source, err)
+		}
+		reader = file
+	}
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
 	if err != nil {
-		log.Fatalf("[ERROR] - Error reading response, err")
+		return nil, fmt.Errorf("error reading response: %w", err)
 	}
-	words := strings.Fields(string(body))
 
-	for _, word := range words {
+	return Parse(string(body)), nil
+}
+
+// Parse builds a word bank from a whitespace separated list of words, keeping the words that are
+// longer than 3 characters and composed of letters.
+//
+// Parameters:
+//   - words: The whitespace separated list of words.
+//
+// Returns:
+//   - utils.WordBank: The set of valid, lowercased words.
+func Parse(words string) utils.WordBank {
+	wordBankMap := make(utils.WordBank)
+	for _, word := range strings.Fields(words) {
 		if utf8.RuneCountInString(word) > 3 && utils.IsLetter(word) {
 			wordBankMap[strings.ToLower(word)] = struct{}{}
 		}
 	}
+	return wordBankMap
+}
 
-	wordBankChannel <- wordBankMap
-	return nil
+// Write writes the words of the word bank to the writer, sorted and one word per line.
+// The output can be loaded again with Load.
+//
+// Parameters:
+//   - w: The writer to write the words to.
+//   - wordBank: The word bank to write.
+//
+// Returns:
+//   - error: An error if the words cannot be written.
+func Write(w io.Writer, wordBank utils.WordBank) error {
+	words := make([]string, 0, len(wordBank))
+	for word := range wordBank {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	buffered := bufio.NewWriter(w)
+	for _, word := range words {
+		if _, err := buffered.WriteString(word + "\n"); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
 }
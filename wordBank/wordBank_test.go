@@ -1,10 +1,12 @@
 package wordBank
 
 import (
-	"firefly-assignment/config"
+	"errors"
 	"firefly-assignment/utils"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -18,8 +20,6 @@ var httpGetFunc = func(url string) (*http.Response, error) {
 }
 
 func TestInitialize(t *testing.T) {
-	config.LoadConfig()
-
 	tests := []struct {
 		name          string
 		mockResponse  string
@@ -60,7 +60,7 @@ func TestInitialize(t *testing.T) {
 			wordBankChannel := make(chan utils.WordBank, 1)
 
 			// Call Initialize function
-			go Initialize(wordBankChannel)
+			go NewBank(WithHTTPGet(httpGetFunc)).Initialize(wordBankChannel)
 
 			// Get the word bank from the channel
 			wordBank := <-wordBankChannel
@@ -74,3 +74,145 @@ func TestInitialize(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("Apple banana\ndog 12345 Cherry\n"), 0644); err != nil {
+		t.Fatalf("could not write word list: %v", err)
+	}
+
+	wordBank, err := NewBank().Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading word bank: %v", err)
+	}
+
+	expectedWords := []string{"apple", "banana", "cherry"}
+	if len(wordBank) != len(expectedWords) {
+		t.Fatalf("expected %d words, got %d (%v)", len(expectedWords), len(wordBank), wordBank)
+	}
+	for _, word := range expectedWords {
+		if _, exists := wordBank[word]; !exists {
+			t.Errorf("expected word %s in word bank, but it was not found", word)
+		}
+	}
+
+	if _, err := NewBank().Load(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("expected error loading missing word list")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var output strings.Builder
+	if err := Write(&output, utils.WordBank{"cherry": {}, "apple": {}, "banana": {}}); err != nil {
+		t.Fatalf("unexpected error writing word bank: %v", err)
+	}
+
+	expected := "apple\nbanana\ncherry\n"
+	if output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+}
+
+func TestInitializeLanguages(t *testing.T) {
+	responses := map[string]string{
+		"https://example.com/en.txt": "apple that banana",
+		"https://example.com/de.txt": "apfel dass banane",
+	}
+	mockHTTPGet := func(url string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(responses[url])),
+		}, nil
+	}
+
+	bank := NewBank(
+		WithURL("https://example.com/en.txt"),
+		WithLanguageURLs(map[string]string{"DE": "https://example.com/de.txt"}),
+		WithStopwordsRemoved(true),
+		WithHTTPGet(mockHTTPGet),
+	)
+
+	wordBanksChannel := make(chan utils.LanguageWordBanks, 1)
+	go bank.InitializeLanguages(wordBanksChannel)
+	wordBanks := <-wordBanksChannel
+
+	expectedWords := map[string][]string{
+		"en": {"apple", "banana"},
+		"de": {"apfel", "banane"},
+	}
+	if len(wordBanks) != len(expectedWords) {
+		t.Fatalf("expected %d word banks, got %d", len(expectedWords), len(wordBanks))
+	}
+	for language, words := range expectedWords {
+		if len(wordBanks[language]) != len(words) {
+			t.Errorf("expected %d words in %v word bank, got %v", len(words), language, wordBanks[language])
+		}
+		for _, word := range words {
+			if _, exists := wordBanks[language][word]; !exists {
+				t.Errorf("expected word %s in %v word bank, but it was not found", word, language)
+			}
+		}
+	}
+}
+
+func TestInitializeLanguagesError(t *testing.T) {
+	bank := NewBank(
+		WithURL("https://example.com/en.txt"),
+		WithHTTPGet(func(url string) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}),
+	)
+
+	wordBanksChannel := make(chan utils.LanguageWordBanks, 1)
+	err := bank.InitializeLanguages(wordBanksChannel)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the load error to be returned, got %v", err)
+	}
+	if len(wordBanksChannel) != 0 {
+		t.Error("expected no word banks to be sent after an error")
+	}
+}
+
+// closeTracker records whether a response body was closed.
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLoadErrorStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		status      string
+		expectError bool
+	}{
+		{name: "OK", statusCode: 200, status: "200 OK"},
+		{name: "Not found", statusCode: 404, status: "404 Not Found", expectError: true},
+		{name: "Service unavailable", statusCode: 503, status: "503 Service Unavailable", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeTracker{Reader: strings.NewReader("<html>Page not found</html>")}
+			bank := NewBank(WithHTTPGet(func(url string) (*http.Response, error) {
+				return &http.Response{StatusCode: tt.statusCode, Status: tt.status, Body: body}, nil
+			}))
+
+			_, err := bank.Load("https://example.com/de.txt")
+			if tt.expectError && (err == nil || !strings.Contains(err.Error(), tt.status)) {
+				t.Errorf("expected an error with the status %q, got %v", tt.status, err)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !body.closed {
+				t.Error("expected the response body to be closed")
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Package wordDiff compares the word frequencies of two runs: which words rose or fell in rank, which are new
+and which vanished, and how significant each change in frequency is, as a log-likelihood ratio (Dunning's G²).
+The log-likelihood ratio accounts for the sizes of both runs, so that a change of a frequent word in a large run
+is not overstated, and a word that appears a handful of times in only one run is not ignored.
+*/
+package wordDiff
+
+import (
+	"firefly-assignment/utils"
+	"math"
+	"sort"
+)
+
+// Statuses of a word in the second run, compared with the first run.
+const (
+	StatusNew       = "new"
+	StatusVanished  = "vanished"
+	StatusRose      = "rose"
+	StatusFell      = "fell"
+	StatusUnchanged = "unchanged"
+)
+
+// Change is the change of a word from the first run (A) to the second run (B).
+type Change struct {
+	Word       string  `json:"word"`
+	Status     string  `json:"status"`
+	FrequencyA int32   `json:"frequency_a"`
+	FrequencyB int32   `json:"frequency_b"`
+	RankA      int     `json:"rank_a,omitempty"`
+	RankB      int     `json:"rank_b,omitempty"`
+	RankChange int     `json:"rank_change"`
+	LLR        float64 `json:"llr"`
+}
+
+// Compare compares the word frequencies of two runs.
+// A word that is in only one run is new or vanished; otherwise it rose or fell if its rank changed.
+// Ranks start at 1 for the most frequent word, and ties are ranked alphabetically.
+//
+// Parameters:
+//   - a: The word frequencies of the first run.
+//   - b: The word frequencies of the second run.
+//
+// Returns:
+//   - []Change: The change of every word of either run, most significant (highest log-likelihood ratio) first.
+func Compare(a utils.WordFrequencyMap, b utils.WordFrequencyMap) []Change {
+	ranksA, totalA := rank(a)
+	ranksB, totalB := rank(b)
+
+	var changes []Change
+	for word := range union(a, b) {
+		change := Change{
+			Word:       word,
+			FrequencyA: a[word],
+			FrequencyB: b[word],
+			RankA:      ranksA[word],
+			RankB:      ranksB[word],
+			LLR:        LogLikelihoodRatio(int64(a[word]), totalA, int64(b[word]), totalB),
+		}
+
+		switch {
+		case change.RankA == 0:
+			change.Status = StatusNew
+		case change.RankB == 0:
+			change.Status = StatusVanished
+		case change.RankB < change.RankA:
+			change.Status = StatusRose
+		case change.RankB > change.RankA:
+			change.Status = StatusFell
+		default:
+			change.Status = StatusUnchanged
+		}
+		if change.RankA != 0 && change.RankB != 0 {
+			change.RankChange = change.RankA - change.RankB
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].LLR != changes[j].LLR {
+			return changes[i].LLR > changes[j].LLR
+		}
+		return changes[i].Word < changes[j].Word
+	})
+	return changes
+}
+
+// LogLikelihoodRatio returns the log-likelihood ratio (G²) of a word that occurs countA times among totalA
+// words of the first run, and countB times among totalB words of the second run. It is 0 when the relative
+// frequencies are equal, and grows with the significance of the difference; above 3.84, the difference is
+// significant at the 95% level.
+//
+// Parameters:
+//   - countA: The frequency of the word in the first run.
+//   - totalA: The total frequency of all words of the first run.
+//   - countB: The frequency of the word in the second run.
+//   - totalB: The total frequency of all words of the second run.
+//
+// Returns:
+//   - float64: The log-likelihood ratio.
+func LogLikelihoodRatio(countA int64, totalA int64, countB int64, totalB int64) float64 {
+	if totalA == 0 || totalB == 0 {
+		return 0
+	}
+
+	expectedA := float64(totalA) * float64(countA+countB) / float64(totalA+totalB)
+	expectedB := float64(totalB) * float64(countA+countB) / float64(totalA+totalB)
+	return 2 * (term(countA, expectedA) + term(countB, expectedB))
+}
+
+// term returns observed * ln(observed / expected), which is 0 for a word that was not observed.
+func term(observed int64, expected float64) float64 {
+	if observed == 0 {
+		return 0
+	}
+	return float64(observed) * math.Log(float64(observed)/expected)
+}
+
+// rank returns the rank of each word by descending frequency, ties ranked alphabetically,
+// and the total frequency of all words.
+func rank(frequencies utils.WordFrequencyMap) (map[string]int, int64) {
+	words := make([]string, 0, len(frequencies))
+	var total int64
+	for word, count := range frequencies {
+		words = append(words, word)
+		total += int64(count)
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if frequencies[words[i]] != frequencies[words[j]] {
+			return frequencies[words[i]] > frequencies[words[j]]
+		}
+		return words[i] < words[j]
+	})
+
+	ranks := make(map[string]int, len(words))
+	for i, word := range words {
+		ranks[word] = i + 1
+	}
+	return ranks, total
+}
+
+// union returns the set of words of both maps.
+func union(a utils.WordFrequencyMap, b utils.WordFrequencyMap) map[string]struct{} {
+	words := make(map[string]struct{}, len(a)+len(b))
+	for word := range a {
+		words[word] = struct{}{}
+	}
+	for word := range b {
+		words[word] = struct{}{}
+	}
+	return words
+}
@@ -0,0 +1,85 @@
+package wordDiff
+
+import (
+	"firefly-assignment/utils"
+	"math"
+	"testing"
+)
+
+func TestLogLikelihoodRatio(t *testing.T) {
+	tests := []struct {
+		name                           string
+		countA, totalA, countB, totalB int64
+		expected                       float64
+	}{
+		{name: "Same relative frequency", countA: 10, totalA: 100, countB: 20, totalB: 200, expected: 0},
+		{name: "Vanished word", countA: 10, totalA: 100, countB: 0, totalB: 100, expected: 20 * math.Log(2)},
+		{name: "New word", countA: 0, totalA: 100, countB: 10, totalB: 100, expected: 20 * math.Log(2)},
+		{name: "Empty run", countA: 0, totalA: 0, countB: 10, totalB: 100, expected: 0},
+		{
+			name:   "Changed frequency",
+			countA: 30, totalA: 1000, countB: 10, totalB: 1000,
+			// Expected counts are 20 each: 2 * (30 ln(30/20) + 10 ln(10/20))
+			expected: 2 * (30*math.Log(1.5) + 10*math.Log(0.5)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LogLikelihoodRatio(tt.countA, tt.totalA, tt.countB, tt.totalB)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := utils.WordFrequencyMap{"apple": 10, "banana": 5, "cherry": 5, "date": 2}
+	b := utils.WordFrequencyMap{"apple": 10, "banana": 2, "cherry": 8, "elder": 2}
+
+	changes := Compare(a, b)
+	byWord := make(map[string]Change)
+	for _, change := range changes {
+		byWord[change.Word] = change
+	}
+
+	tests := []struct {
+		word               string
+		expectedStatus     string
+		expectedRankA      int
+		expectedRankB      int
+		expectedRankChange int
+	}{
+		{word: "apple", expectedStatus: StatusUnchanged, expectedRankA: 1, expectedRankB: 1},
+		{word: "banana", expectedStatus: StatusFell, expectedRankA: 2, expectedRankB: 3, expectedRankChange: -1},
+		{word: "cherry", expectedStatus: StatusRose, expectedRankA: 3, expectedRankB: 2, expectedRankChange: 1},
+		{word: "date", expectedStatus: StatusVanished, expectedRankA: 4},
+		{word: "elder", expectedStatus: StatusNew, expectedRankB: 4},
+	}
+
+	if len(changes) != len(tests) {
+		t.Fatalf("expected %v changes, got %+v", len(tests), changes)
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			change := byWord[tt.word]
+			if change.Status != tt.expectedStatus || change.RankA != tt.expectedRankA || change.RankB != tt.expectedRankB || change.RankChange != tt.expectedRankChange {
+				t.Errorf("expected %v (rank %v -> %v, change %v), got %+v", tt.expectedStatus, tt.expectedRankA, tt.expectedRankB, tt.expectedRankChange, change)
+			}
+			if change.FrequencyA != a[tt.word] || change.FrequencyB != b[tt.word] {
+				t.Errorf("expected frequencies %v -> %v, got %+v", a[tt.word], b[tt.word], change)
+			}
+		})
+	}
+
+	// The changes are sorted by significance; both runs have 22 words, so apple did not change at all.
+	for i := 1; i < len(changes); i++ {
+		if changes[i].LLR > changes[i-1].LLR {
+			t.Errorf("expected changes sorted by descending LLR, got %+v", changes)
+		}
+	}
+	if changes[len(changes)-1].Word != "apple" || changes[len(changes)-1].LLR != 0 {
+		t.Errorf("expected apple to be the least significant change, got %+v", changes[len(changes)-1])
+	}
+}
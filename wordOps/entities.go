@@ -0,0 +1,134 @@
+package wordOps
+
+import (
+	"firefly-assignment/utils"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CountWordsAndEntities updates the word and entity frequency maps from the given article words.
+//
+// Proper nouns are detected with a capitalization heuristic: a capitalized word in the middle of a
+// sentence is treated as (part of) a named entity, while a capitalized word at the start of a
+// sentence is only treated as an entity if it is not a common word in the word bank. Consecutive
+// capitalized words are joined into a single entity (e.g. "Tim Cook"). All remaining words are
+// counted as common words, normalized in the same way as CountWords (see NormalizeWord).
+//
+// Parameters:
+//   - articleWords: A slice of words from the article to be processed.
+//   - wordBank: A set of valid words used for filtering the common words.
+//   - wordFrequencyMap: A map where common word counts will be updated.
+//   - entityFrequencyMap: A map where entity counts will be updated, keyed by the entity as written.
+func CountWordsAndEntities(articleWords []string, wordBank utils.WordBank, wordFrequencyMap utils.WordFrequencyMap, entityFrequencyMap utils.WordFrequencyMap) {
+	countWordsAndEntities(articleWords, wordBank, wordFrequencyMap, entityFrequencyMap, nil)
+}
+
+// countWordsAndEntities counts the common words and the entities of the article, and the words that are
+// neither in the ignored frequency map, if any.
+func countWordsAndEntities(articleWords []string, wordBank utils.WordBank, wordFrequencyMap utils.WordFrequencyMap, entityFrequencyMap utils.WordFrequencyMap, ignoredFrequencyMap utils.WordFrequencyMap) {
+	var entity []string
+	flushEntity := func() {
+		if len(entity) > 0 {
+			entityFrequencyMap[strings.Join(entity, " ")]++
+			entity = entity[:0]
+		}
+	}
+
+	sentenceStart := true
+	for _, rawWord := range articleWords {
+		word := cleanWord(rawWord)
+		isSentenceEnd := endsSentence(rawWord)
+		// A break in the entity, such as a comma or closing bracket, ends the current entity.
+		breaksEntity := isSentenceEnd || strings.ContainsAny(lastRune(rawWord), ",;:)]\"'”’")
+
+		if word == "" {
+			flushEntity()
+			sentenceStart = sentenceStart || isSentenceEnd
+			continue
+		}
+
+		normalizedWord := strings.ToLower(word)
+		_, inWordBank := wordBank[normalizedWord]
+
+		// At the start of a sentence, short words (which are never in the word bank, e.g. "The") and
+		// common words are not considered entities, unless they are acronyms.
+		ambiguousStart := sentenceStart && !isAcronym(word) && (inWordBank || utf8.RuneCountInString(word) <= 3)
+		if isCapitalized(word) && !ambiguousStart {
+			entity = append(entity, word)
+		} else {
+			flushEntity()
+			if inWordBank {
+				wordFrequencyMap[normalizedWord]++
+			} else if ignoredFrequencyMap != nil {
+				ignoredFrequencyMap[normalizedWord]++
+			}
+		}
+
+		if breaksEntity {
+			flushEntity()
+		}
+		sentenceStart = isSentenceEnd
+	}
+	flushEntity()
+}
+
+// GetTopNEntities returns the top 'n' named entities with the highest frequencies.
+//
+// Parameters:
+//   - n: The number of top entities to return.
+//   - entityFrequencyMap: A map where keys are entities and values are their frequencies.
+//
+// Returns:
+//   - []utils.WordFreq: A slice containing the top 'n' entities with their frequencies, sorted by frequency.
+func GetTopNEntities(n int, entityFrequencyMap utils.WordFrequencyMap) []utils.WordFreq {
+	return GetTopNWords(n, entityFrequencyMap)
+}
+
+// isCapitalized reports whether the word starts with an uppercase letter. Single letters such as "I"
+// or "A" are not considered capitalized words.
+func isCapitalized(word string) bool {
+	if utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// isAcronym reports whether the word is made up of at least two uppercase letters (e.g. "NASA").
+func isAcronym(word string) bool {
+	if utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// endsSentence reports whether the raw word closes a sentence, ignoring trailing quotes and brackets.
+func endsSentence(rawWord string) bool {
+	trimmed := strings.TrimRight(rawWord, "\"'”’)]")
+	return strings.HasSuffix(trimmed, ".") || strings.HasSuffix(trimmed, "!") || strings.HasSuffix(trimmed, "?")
+}
+
+// trimPossessive removes a trailing possessive suffix (e.g. "Apple's" -> "Apple").
+func trimPossessive(word string) string {
+	for _, suffix := range []string{"'s", "’s"} {
+		if trimmed, found := strings.CutSuffix(word, suffix); found {
+			return trimmed
+		}
+	}
+	return word
+}
+
+// lastRune returns the last rune of the word as a string.
+func lastRune(word string) string {
+	r, _ := utf8.DecodeLastRuneInString(word)
+	if r == utf8.RuneError {
+		return ""
+	}
+	return string(r)
+}
@@ -0,0 +1,142 @@
+package wordOps
+
+import (
+	"firefly-assignment/utils"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountWordsAndEntities(t *testing.T) {
+	wordBank := utils.WordBank{
+		"apple":     struct{}{},
+		"announced": struct{}{},
+		"phone":     struct{}{},
+		"today":     struct{}{},
+		"with":      struct{}{},
+		"this":      struct{}{},
+		"grows":     struct{}{},
+		"trees":     struct{}{},
+	}
+
+	tests := []struct {
+		name             string
+		article          string
+		expectedWords    utils.WordFrequencyMap
+		expectedEntities utils.WordFrequencyMap
+	}{
+		{
+			name:             "Capitalized word mid-sentence is an entity",
+			article:          "Today Apple announced a phone.",
+			expectedWords:    utils.WordFrequencyMap{"today": 1, "announced": 1, "phone": 1},
+			expectedEntities: utils.WordFrequencyMap{"Apple": 1},
+		},
+		{
+			name:             "Common word at sentence start is not an entity",
+			article:          "This apple grows on trees. Apple grows today.",
+			expectedWords:    utils.WordFrequencyMap{"this": 1, "apple": 2, "grows": 2, "trees": 1, "today": 1},
+			expectedEntities: utils.WordFrequencyMap{},
+		},
+		{
+			name:             "Short word at sentence start is not an entity",
+			article:          "The phone announced today. But Apple grows.",
+			expectedWords:    utils.WordFrequencyMap{"phone": 1, "announced": 1, "today": 1, "grows": 1},
+			expectedEntities: utils.WordFrequencyMap{"Apple": 1},
+		},
+		{
+			name:             "Unknown capitalized word at sentence start is an entity",
+			article:          "Engadget announced a phone.",
+			expectedWords:    utils.WordFrequencyMap{"announced": 1, "phone": 1},
+			expectedEntities: utils.WordFrequencyMap{"Engadget": 1},
+		},
+		{
+			name:             "Consecutive capitalized words form one entity",
+			article:          "Today Tim Cook announced a phone with Apple's team.",
+			expectedWords:    utils.WordFrequencyMap{"today": 1, "announced": 1, "phone": 1, "with": 1},
+			expectedEntities: utils.WordFrequencyMap{"Tim Cook": 1, "Apple": 1},
+		},
+		{
+			name:             "Punctuation breaks entities",
+			article:          "Today Apple, Google and NASA announced a phone.",
+			expectedWords:    utils.WordFrequencyMap{"today": 1, "announced": 1, "phone": 1},
+			expectedEntities: utils.WordFrequencyMap{"Apple": 1, "Google": 1, "NASA": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordFrequencyMap := make(utils.WordFrequencyMap)
+			entityFrequencyMap := make(utils.WordFrequencyMap)
+
+			CountWordsAndEntities(strings.Fields(tt.article), wordBank, wordFrequencyMap, entityFrequencyMap)
+
+			if len(wordFrequencyMap) != len(tt.expectedWords) {
+				t.Errorf("expected words %v, got %v", tt.expectedWords, wordFrequencyMap)
+			}
+			for word, freq := range tt.expectedWords {
+				if wordFrequencyMap[word] != freq {
+					t.Errorf("expected frequency of word %q to be %d, got %d", word, freq, wordFrequencyMap[word])
+				}
+			}
+
+			if len(entityFrequencyMap) != len(tt.expectedEntities) {
+				t.Errorf("expected entities %v, got %v", tt.expectedEntities, entityFrequencyMap)
+			}
+			for entity, freq := range tt.expectedEntities {
+				if entityFrequencyMap[entity] != freq {
+					t.Errorf("expected frequency of entity %q to be %d, got %d", entity, freq, entityFrequencyMap[entity])
+				}
+			}
+		})
+	}
+}
+
+func TestGetTopNEntities(t *testing.T) {
+	entityFrequencyMap := utils.WordFrequencyMap{"Apple": 3, "Google": 5, "Tim Cook": 1}
+
+	result := GetTopNEntities(2, entityFrequencyMap)
+
+	expected := []utils.WordFreq{
+		{Word: "Google", Frequency: 5},
+		{Word: "Apple", Frequency: 3},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d entities, got %d", len(expected), len(result))
+	}
+	for i, entity := range result {
+		if entity != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], entity)
+		}
+	}
+}
+
+func TestCountWordsMatchesCountWordsAndEntities(t *testing.T) {
+	wordBank := utils.WordBank{"phone": struct{}{}, "screen": struct{}{}, "new": struct{}{}}
+
+	tests := []struct {
+		name     string
+		article  string
+		expected utils.WordFrequencyMap
+	}{
+		{name: "Punctuation", article: "a new phone. the phone, (screen)", expected: utils.WordFrequencyMap{"new": 1, "phone": 2, "screen": 1}},
+		{name: "Possessive suffix", article: "the phone's screen and the phone’s", expected: utils.WordFrequencyMap{"phone": 2, "screen": 1}},
+		{name: "Quotes", article: `a "new" 'phone'`, expected: utils.WordFrequencyMap{"new": 1, "phone": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := strings.Fields(tt.article)
+			wordCounts := make(utils.WordFrequencyMap)
+			CountWords(words, wordBank, wordCounts)
+			entityPathCounts := make(utils.WordFrequencyMap)
+			CountWordsAndEntities(words, wordBank, entityPathCounts, make(utils.WordFrequencyMap))
+
+			if !reflect.DeepEqual(wordCounts, tt.expected) {
+				t.Errorf("CountWords: expected %v, got %v", tt.expected, wordCounts)
+			}
+			if !reflect.DeepEqual(entityPathCounts, tt.expected) {
+				t.Errorf("CountWordsAndEntities: expected %v, got %v", tt.expected, entityPathCounts)
+			}
+		})
+	}
+}
@@ -1,19 +1,21 @@
 // Package wordOps provides operations for processing word frequencies,
 // including counting word occurrences and extracting the top N frequent words.
+// The operations hold no state of their own: callers that share a frequency map
+// between goroutines must synchronize their access to it.
 package wordOps
 
 import (
 	"container/heap"
 	"firefly-assignment/minheap"
 	"firefly-assignment/utils"
+	"math"
+	"sort"
 	"strings"
-	"sync"
 )
 
-var mutex sync.Mutex
-
 // GetTopNWords returns the top 'n' words with the highest frequencies from the given word frequency map.
-// It uses a min-heap to efficiently keep track of the top words.
+// It uses a min-heap to efficiently keep track of the top words. Words with the same frequency are
+// ordered alphabetically, so that the result does not depend on the iteration order of the map.
 //
 // Parameters:
 //   - n: The number of top words to return.
@@ -22,9 +24,6 @@ var mutex sync.Mutex
 // Returns:
 //   - []utils.WordFreq: A slice containing the top 'n' words with their frequencies, sorted by frequency.
 func GetTopNWords(n int, wordFrequencyMap utils.WordFrequencyMap) []utils.WordFreq {
-	mutex.Lock()
-	defer mutex.Unlock()
-
 	// Initialize heatmap
 	h := &minheap.MinHeap{}
 	heap.Init(h)
@@ -32,7 +31,7 @@ func GetTopNWords(n int, wordFrequencyMap utils.WordFrequencyMap) []utils.WordFr
 	for word, count := range wordFrequencyMap {
 		if h.Len() < n {
 			heap.Push(h, utils.WordFreq{Word: word, Frequency: count})
-		} else if count > (*h)[0].Frequency {
+		} else if top := (*h)[0]; count > top.Frequency || (count == top.Frequency && word < top.Word) {
 			heap.Pop(h)
 			heap.Push(h, utils.WordFreq{Word: word, Frequency: count})
 		}
@@ -59,13 +58,112 @@ func GetTopNWords(n int, wordFrequencyMap utils.WordFrequencyMap) []utils.WordFr
 //   - wordBank: A set of valid words used for filtering the article words.
 //   - wordFrequencyMap: A map where word counts will be updated.
 func CountWords(articleWords []string, wordBank utils.WordBank, wordFrequencyMap utils.WordFrequencyMap) {
-	mutex.Lock()
-	defer mutex.Unlock()
+	countWords(articleWords, wordBank, wordFrequencyMap, nil)
+}
+
+// IgnoredWords returns the words of an article that are not counted: the words that are not in the word bank,
+// and, if entities are counted, that are not part of an entity either.
+//
+// Parameters:
+//   - articleWords: A slice of words from the article to be processed.
+//   - wordBank: A set of valid words used for filtering the article words.
+//   - withEntities: Whether the article is counted with CountWordsAndEntities rather than CountWords.
+//
+// Returns:
+//   - utils.WordFrequencyMap: The frequencies of the ignored words, normalized (see NormalizeWord).
+func IgnoredWords(articleWords []string, wordBank utils.WordBank, withEntities bool) utils.WordFrequencyMap {
+	ignoredFrequencyMap := make(utils.WordFrequencyMap)
+	if withEntities {
+		countWordsAndEntities(articleWords, wordBank, make(utils.WordFrequencyMap), make(utils.WordFrequencyMap), ignoredFrequencyMap)
+	} else {
+		countWords(articleWords, wordBank, make(utils.WordFrequencyMap), ignoredFrequencyMap)
+	}
+	return ignoredFrequencyMap
+}
 
+// countWords counts the words of the article that exist in the word bank, and the other words in the
+// ignored frequency map, if any.
+func countWords(articleWords []string, wordBank utils.WordBank, wordFrequencyMap utils.WordFrequencyMap, ignoredFrequencyMap utils.WordFrequencyMap) {
 	for _, word := range articleWords {
-		normalizedWord := strings.ToLower(word)
+		normalizedWord := NormalizeWord(word)
 		if _, exists := wordBank[normalizedWord]; exists {
 			wordFrequencyMap[normalizedWord]++
+		} else if ignoredFrequencyMap != nil && normalizedWord != "" {
+			ignoredFrequencyMap[normalizedWord]++
+		}
+	}
+}
+
+// NormalizeWord returns the form of a raw article word that is looked up in the word bank and counted:
+// without surrounding punctuation or possessive suffix, and in lowercase (e.g. "Phone's," -> "phone").
+//
+// Parameters:
+//   - rawWord: The word as it appears in the article.
+//
+// Returns:
+//   - string: The normalized word, or "" if the raw word has no letters.
+func NormalizeWord(rawWord string) string {
+	return strings.ToLower(cleanWord(rawWord))
+}
+
+// cleanWord removes the surrounding punctuation and the possessive suffix of a raw word, keeping its case.
+func cleanWord(rawWord string) string {
+	return trimPossessive(utils.TrimNonLetters(rawWord))
+}
+
+// MergeFrequencies adds the frequencies of the source map to the destination map.
+//
+// Parameters:
+//   - destination: The map where the frequencies are added.
+//   - source: The map with the frequencies to add.
+func MergeFrequencies(destination utils.WordFrequencyMap, source utils.WordFrequencyMap) {
+	for word, count := range source {
+		destination[word] += count
+	}
+}
+
+// CountDocument updates the document frequency map with the words of an article: every word that occurs
+// in the article is counted once, however often it occurs.
+//
+// Parameters:
+//   - documentFrequencyMap: A map where keys are words and values are the number of articles they occur in.
+//   - articleCounts: The word counts of the article.
+func CountDocument(documentFrequencyMap utils.WordFrequencyMap, articleCounts utils.WordFrequencyMap) {
+	for word, count := range articleCounts {
+		if count > 0 {
+			documentFrequencyMap[word]++
+		}
+	}
+}
+
+// GetTopNTFIDF returns the top 'n' words with the highest TF-IDF scores. The score of a word is its
+// frequency over all articles, weighted by the smoothed inverse document frequency ln((1+N)/(1+df)) + 1,
+// so that words that occur in few articles rank higher than words that occur in all of them.
+// Words with the same score are ordered alphabetically.
+//
+// Parameters:
+//   - n: The number of top words to return.
+//   - wordFrequencyMap: A map where keys are words and values are their frequencies.
+//   - documentFrequencyMap: A map where keys are words and values are the number of articles they occur in.
+//   - documents: The number of articles.
+//
+// Returns:
+//   - []utils.WordScore: A slice containing the top 'n' words with their scores, sorted by score.
+func GetTopNTFIDF(n int, wordFrequencyMap utils.WordFrequencyMap, documentFrequencyMap utils.WordFrequencyMap, documents int) []utils.WordScore {
+	scores := make([]utils.WordScore, 0, len(wordFrequencyMap))
+	for word, count := range wordFrequencyMap {
+		idf := math.Log(float64(1+documents)/float64(1+documentFrequencyMap[word])) + 1
+		scores = append(scores, utils.WordScore{Word: word, Score: float64(count) * idf})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
 		}
+		return scores[i].Word < scores[j].Word
+	})
+
+	if len(scores) > n {
+		scores = scores[:n]
 	}
+	return scores
 }
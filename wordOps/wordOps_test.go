@@ -2,6 +2,9 @@ package wordOps
 
 import (
 	"firefly-assignment/utils"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -130,3 +133,155 @@ func TestCountWords(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeWord(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawWord  string
+		expected string
+	}{
+		{name: "Lowercase word", rawWord: "phone", expected: "phone"},
+		{name: "Capitalized word", rawWord: "Phone", expected: "phone"},
+		{name: "Trailing punctuation", rawWord: "phone.", expected: "phone"},
+		{name: "Surrounding quotes", rawWord: "\"phone\",", expected: "phone"},
+		{name: "Possessive suffix", rawWord: "Phone's", expected: "phone"},
+		{name: "No letters", rawWord: "--", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeWord(tt.rawWord); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIgnoredWords(t *testing.T) {
+	wordBank := utils.WordBank{"announced": struct{}{}, "phone": struct{}{}}
+
+	tests := []struct {
+		name         string
+		article      string
+		withEntities bool
+		expected     utils.WordFrequencyMap
+	}{
+		{
+			name:     "Words not in the word bank",
+			article:  "Tim Cook announced a phone, a Phone.",
+			expected: utils.WordFrequencyMap{"tim": 1, "cook": 1, "a": 2},
+		},
+		{
+			name:         "Entity words are not ignored",
+			article:      "The boss Tim Cook announced a phone.",
+			withEntities: true,
+			expected:     utils.WordFrequencyMap{"the": 1, "boss": 1, "a": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IgnoredWords(strings.Fields(tt.article), wordBank, tt.withEntities)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMergeFrequencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination utils.WordFrequencyMap
+		source      utils.WordFrequencyMap
+		expected    utils.WordFrequencyMap
+	}{
+		{
+			name:        "Merge into empty map",
+			destination: utils.WordFrequencyMap{},
+			source:      utils.WordFrequencyMap{"test": 2},
+			expected:    utils.WordFrequencyMap{"test": 2},
+		},
+		{
+			name:        "Add overlapping words",
+			destination: utils.WordFrequencyMap{"test": 2, "example": 1},
+			source:      utils.WordFrequencyMap{"test": 3, "word": 1},
+			expected:    utils.WordFrequencyMap{"test": 5, "example": 1, "word": 1},
+		},
+		{
+			name:        "Merge empty map",
+			destination: utils.WordFrequencyMap{"test": 2},
+			source:      utils.WordFrequencyMap{},
+			expected:    utils.WordFrequencyMap{"test": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			MergeFrequencies(tt.destination, tt.source)
+			if !reflect.DeepEqual(tt.destination, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, tt.destination)
+			}
+		})
+	}
+}
+
+func TestGetTopNWordsBreaksTies(t *testing.T) {
+	wordFrequencyMap := utils.WordFrequencyMap{"delta": 2, "alpha": 1, "charlie": 2, "bravo": 2, "echo": 1}
+	expected := []utils.WordFreq{{Word: "bravo", Frequency: 2}, {Word: "charlie", Frequency: 2}, {Word: "delta", Frequency: 2}, {Word: "alpha", Frequency: 1}}
+
+	// Map iteration order is random, so repeat to make a dependency on it show up.
+	for i := 0; i < 20; i++ {
+		if result := GetTopNWords(4, wordFrequencyMap); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("expected %v, got %v", expected, result)
+		}
+	}
+}
+
+func TestCountDocument(t *testing.T) {
+	documentFrequencyMap := utils.WordFrequencyMap{"apple": 1}
+	CountDocument(documentFrequencyMap, utils.WordFrequencyMap{"apple": 5, "phone": 1, "space": 0})
+
+	expected := utils.WordFrequencyMap{"apple": 2, "phone": 1}
+	if !reflect.DeepEqual(documentFrequencyMap, expected) {
+		t.Errorf("expected %v, got %v", expected, documentFrequencyMap)
+	}
+}
+
+func TestGetTopNTFIDF(t *testing.T) {
+	// "the" occurs in all 4 articles, "phone" in 1 of them.
+	wordFrequencyMap := utils.WordFrequencyMap{"the": 10, "phone": 6, "apple": 6, "space": 1}
+	documentFrequencyMap := utils.WordFrequencyMap{"the": 4, "phone": 1, "apple": 1, "space": 2}
+
+	tests := []struct {
+		name          string
+		n             int
+		expectedWords []string
+	}{
+		{name: "Rare words outrank common words", n: 3, expectedWords: []string{"apple", "phone", "the"}},
+		{name: "More words than available", n: 10, expectedWords: []string{"apple", "phone", "the", "space"}},
+		{name: "No words", n: 0, expectedWords: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GetTopNTFIDF(tt.n, wordFrequencyMap, documentFrequencyMap, 4)
+			words := []string{}
+			for _, score := range result {
+				words = append(words, score.Word)
+			}
+			if !reflect.DeepEqual(words, tt.expectedWords) {
+				t.Errorf("expected %v, got %v", tt.expectedWords, words)
+			}
+		})
+	}
+
+	// ln(5/2) + 1 for a word in 1 of 4 articles, and ln(5/5) + 1 = 1 for a word in all of them.
+	result := GetTopNTFIDF(4, wordFrequencyMap, documentFrequencyMap, 4)
+	if expected := 6 * (math.Log(2.5) + 1); result[0].Score != expected {
+		t.Errorf("expected score %v, got %v", expected, result[0].Score)
+	}
+	if result[2].Score != 10 {
+		t.Errorf("expected score 10, got %v", result[2].Score)
+	}
+}